@@ -0,0 +1,144 @@
+// Command healthsecure is the operational entrypoint for the HTTP server
+// and its schema migrations. `healthsecure serve` runs the server under
+// Server's signal-aware lifecycle; `healthsecure migrate ...` applies or
+// inspects migrations without importing GORM's AutoMigrate, which is
+// reserved for local dev/seed use only.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"healthsecure/configs"
+	"healthsecure/internal/database"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "serve":
+		runServe()
+	case "migrate":
+		runMigrate()
+	default:
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func runServe() {
+	config, err := configs.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	if err := database.Initialize(config); err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+
+	// Route registration lives with the handlers package; this entrypoint
+	// only owns the listen/serve/drain lifecycle.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		if err := database.Health(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server, err := NewServer(mux, config)
+	if err != nil {
+		log.Fatalf("failed to configure server: %v", err)
+	}
+	if err := server.Run(context.Background()); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
+}
+
+func runMigrate() {
+	if len(os.Args) < 3 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	config, err := configs.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	if err := database.Initialize(config); err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer database.Close()
+
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		log.Fatalf("failed to get underlying sql.DB: %v", err)
+	}
+
+	migrator, err := database.NewMigrator(sqlDB, database.DB.Dialector.Name())
+	if err != nil {
+		log.Fatalf("failed to initialize migrator: %v", err)
+	}
+	defer migrator.Close()
+
+	subcommand := os.Args[2]
+	args := os.Args[3:]
+
+	switch subcommand {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		err = migrator.Down()
+	case "goto":
+		version, perr := requireVersionArg(args, "goto")
+		if perr != nil {
+			log.Fatal(perr)
+		}
+		err = migrator.Goto(version)
+	case "force":
+		version, perr := requireVersionArg(args, "force")
+		if perr != nil {
+			log.Fatal(perr)
+		}
+		err = migrator.Force(int(version))
+	case "version":
+		var version uint
+		var dirty bool
+		version, dirty, err = migrator.Version()
+		if err == nil {
+			fmt.Printf("version: %d, dirty: %t\n", version, dirty)
+		}
+	default:
+		printUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		log.Fatalf("migrate %s failed: %v", subcommand, err)
+	}
+}
+
+func requireVersionArg(args []string, subcommand string) (uint, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("migrate %s requires exactly one version argument", subcommand)
+	}
+	version, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid version %q: %w", args[0], err)
+	}
+	return uint(version), nil
+}
+
+func printUsage() {
+	fmt.Println("Usage: healthsecure <serve|migrate <up|down|goto <version>|force <version>|version>>")
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"healthsecure/configs"
+	"healthsecure/internal/database"
+)
+
+// Server owns the HTTP listener, the background cleanup scheduler, and the
+// database connection for a single process lifetime, and coordinates
+// shutting all three down together on SIGINT/SIGTERM. Following the
+// cc-backend pattern, it binds the listener (and, for TLS, reads the
+// certificate/key) while still root, then drops to an unprivileged
+// user:group before serving any requests.
+type Server struct {
+	config *configs.Config
+	http   *http.Server
+	certs  *reloadableCert
+}
+
+// NewServer wires handler behind an *http.Server configured from
+// config.Server. Route construction lives wherever the handlers are
+// assembled; Server itself is only responsible for the listen/serve/drain
+// lifecycle. When config.Server.TLSClientCAFile is set, the server also
+// requires (or, if TLSRequireClientCert is false, merely requests) a client
+// certificate for mTLS callers - see internal/auth.MTLSMiddleware for how
+// that certificate is then mapped to a user.
+func NewServer(handler http.Handler, config *configs.Config) (*Server, error) {
+	httpServer := &http.Server{
+		Addr:              fmt.Sprintf(":%d", config.Server.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	s := &Server{config: config, http: httpServer}
+
+	if config.Server.TLSCertFile != "" {
+		certs, err := newReloadableCert(config.Server.TLSCertFile, config.Server.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		s.certs = certs
+
+		tlsConfig := &tls.Config{GetCertificate: certs.getCertificate}
+
+		if config.Server.TLSClientCAFile != "" {
+			pool, err := loadCertPool(config.Server.TLSClientCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load client CA pool: %w", err)
+			}
+			tlsConfig.ClientCAs = pool
+			if config.Server.TLSRequireClientCert {
+				tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+			} else {
+				tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+			}
+		}
+
+		httpServer.TLSConfig = tlsConfig
+	}
+
+	return s, nil
+}
+
+// reloadableCert holds the currently-serving TLS certificate behind a mutex
+// so reloadOnSIGHUP can swap it out without restarting the listener.
+type reloadableCert struct {
+	certFile, keyFile string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+func newReloadableCert(certFile, keyFile string) (*reloadableCert, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &reloadableCert{certFile: certFile, keyFile: keyFile, cert: &cert}, nil
+}
+
+func (r *reloadableCert) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+func (r *reloadableCert) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	return nil
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return pool, nil
+}
+
+// watchSIGHUP reloads the certificate/key pair whenever the process receives
+// SIGHUP, so a cert rotation doesn't require a restart. The client CA pool
+// that NewServer loaded is not re-read; a changed CA bundle takes effect
+// only after a restart.
+func (s *Server) watchSIGHUP(ctx context.Context) {
+	if s.certs == nil {
+		return
+	}
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			if err := s.certs.reload(); err != nil {
+				log.Printf("Warning: failed to reload TLS certificate on SIGHUP: %v", err)
+			} else {
+				log.Println("Reloaded TLS certificate after SIGHUP")
+			}
+		}
+	}
+}
+
+// Run binds the listener, drops root privileges if configured, starts the
+// database cleanup scheduler, and serves until it receives SIGINT/SIGTERM or
+// ctx is cancelled. It then drains in-flight requests, stops the cleanup
+// scheduler, and closes the database before returning.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to bind %s: %w", s.http.Addr, err)
+	}
+
+	if err := dropPrivileges(s.config); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to drop privileges: %w", err)
+	}
+
+	schedulerCtx, stopScheduler := context.WithCancel(ctx)
+	defer stopScheduler()
+	schedulerDone := database.StartCleanupScheduler(schedulerCtx)
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go s.watchSIGHUP(ctx)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("Listening on %s", s.http.Addr)
+		if s.http.TLSConfig != nil {
+			// cert/key are already loaded into TLSConfig.GetCertificate by
+			// NewServer, so no file paths are needed here.
+			serveErr <- s.http.ServeTLS(listener, "", "")
+		} else {
+			serveErr <- s.http.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server stopped unexpectedly: %w", err)
+		}
+	case <-ctx.Done():
+		log.Println("Shutdown signal received, draining in-flight requests...")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Warning: HTTP server did not shut down cleanly: %v", err)
+	}
+
+	stopScheduler()
+	<-schedulerDone
+
+	if err := database.Close(); err != nil {
+		log.Printf("Warning: failed to close database cleanly: %v", err)
+	}
+
+	log.Println("Shutdown complete")
+	return nil
+}
+
+// dropPrivileges switches the process to config.Server.RunAsUser:RunAsGroup
+// once privileged setup (binding low ports, reading TLS material) is done.
+// It's a no-op if RunAsUser isn't configured, which is the common case when
+// the platform already runs the process unprivileged (e.g. Railway).
+//
+// It uses AllThreadsSyscall rather than the plain Setuid/Setgid wrappers:
+// Go schedules goroutines (including in-flight and future HTTP handlers)
+// across many OS threads, and Linux credentials are per-thread, so a plain
+// Setuid/Setgid would only drop the one thread that happened to call it,
+// leaving the rest of the process - and anything it later schedules onto a
+// different thread - still running as root. AllThreadsSyscall changes every
+// thread's credentials together. Supplementary groups are cleared first, so
+// the process doesn't keep any group membership the root account held that
+// RunAsGroup wasn't explicitly given.
+func dropPrivileges(config *configs.Config) error {
+	if config.Server.RunAsUser == "" {
+		return nil
+	}
+
+	gid, err := strconv.Atoi(config.Server.RunAsGroup)
+	if err != nil {
+		return fmt.Errorf("invalid run-as group %q: %w", config.Server.RunAsGroup, err)
+	}
+	uid, err := strconv.Atoi(config.Server.RunAsUser)
+	if err != nil {
+		return fmt.Errorf("invalid run-as user %q: %w", config.Server.RunAsUser, err)
+	}
+
+	if _, _, errno := syscall.AllThreadsSyscall(syscall.SYS_SETGROUPS, 0, 0, 0); errno != 0 {
+		return fmt.Errorf("setgroups([]) failed: %w", errno)
+	}
+	if _, _, errno := syscall.AllThreadsSyscall(syscall.SYS_SETGID, uintptr(gid), 0, 0); errno != 0 {
+		return fmt.Errorf("setgid(%d) failed: %w", gid, errno)
+	}
+	if _, _, errno := syscall.AllThreadsSyscall(syscall.SYS_SETUID, uintptr(uid), 0, 0); errno != 0 {
+		return fmt.Errorf("setuid(%d) failed: %w", uid, errno)
+	}
+
+	log.Printf("Dropped privileges to uid=%d gid=%d", uid, gid)
+	return nil
+}
@@ -0,0 +1,181 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditEventRow is the durable, append-only storage representation of an
+// AuditEvent. Each row's RowHash commits to PrevHash plus the row's own
+// canonical fields, so altering or deleting a historical row breaks the
+// chain for every row after it.
+type AuditEventRow struct {
+	ID         uint      `gorm:"primaryKey;type:bigint unsigned;autoIncrement"`
+	ActorID    uint      `gorm:"index;type:bigint unsigned"`
+	ActorRole  string    `gorm:"type:varchar(20)"`
+	Action     string    `gorm:"type:varchar(100);index"`
+	Resource   string    `gorm:"type:varchar(255);index"`
+	Success    bool      `gorm:"index"`
+	Reason     string    `gorm:"type:text"`
+	IPAddress  string    `gorm:"type:varchar(45)"`
+	UserAgent  string    `gorm:"type:text"`
+	OccurredAt time.Time `gorm:"index"`
+	PrevHash   string    `gorm:"type:varchar(64)"`
+	RowHash    string    `gorm:"type:varchar(64);index"`
+}
+
+func (AuditEventRow) TableName() string {
+	return "audit_event_chain"
+}
+
+// HMACChainEmitter is the default Emitter: it computes
+// HMAC-SHA256(key, prev_hash || canonical_json(row)) for every inserted row, forming
+// a hash chain that VerifyChain can walk to detect tampering.
+type HMACChainEmitter struct {
+	db  *gorm.DB
+	key []byte
+
+	// mu serializes Emit's read-then-write of the chain head: without it,
+	// two concurrent Emit calls can both read the same PrevHash and insert
+	// concurrently, forking the chain. VerifyChain would then report the
+	// fork as tampering even though it's just a benign race between two
+	// legitimate writers.
+	mu sync.Mutex
+}
+
+func NewHMACChainEmitter(db *gorm.DB, key []byte) *HMACChainEmitter {
+	return &HMACChainEmitter{db: db, key: key}
+}
+
+func (e *HMACChainEmitter) Emit(ctx context.Context, event AuditEvent) error {
+	row := AuditEventRow{
+		ActorID:    event.ActorID,
+		ActorRole:  event.ActorRole,
+		Action:     event.Action,
+		Resource:   event.Resource,
+		Success:    event.Success,
+		Reason:     event.Reason,
+		IPAddress:  event.IPAddress,
+		UserAgent:  event.UserAgent,
+		OccurredAt: event.OccurredAt,
+	}
+	if row.OccurredAt.IsZero() {
+		row.OccurredAt = time.Now()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var last AuditEventRow
+		prevHash := ""
+		if err := tx.Order("id DESC").First(&last).Error; err == nil {
+			prevHash = last.RowHash
+		} else if err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to read chain head: %w", err)
+		}
+
+		row.PrevHash = prevHash
+		hash, err := e.computeHash(prevHash, row)
+		if err != nil {
+			return err
+		}
+		row.RowHash = hash
+
+		if err := tx.Create(&row).Error; err != nil {
+			return fmt.Errorf("failed to append audit event: %w", err)
+		}
+		return nil
+	})
+}
+
+func (e *HMACChainEmitter) computeHash(prevHash string, row AuditEventRow) (string, error) {
+	canonical, err := canonicalJSON(row)
+	if err != nil {
+		return "", fmt.Errorf("failed to canonicalize audit row: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, e.key)
+	mac.Write([]byte(prevHash))
+	mac.Write(canonical)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// canonicalJSON marshals the fields that participate in the hash, excluding
+// the hash columns themselves, in a stable field order.
+func canonicalJSON(row AuditEventRow) ([]byte, error) {
+	return json.Marshal(struct {
+		ActorID    uint      `json:"actor_id"`
+		ActorRole  string    `json:"actor_role"`
+		Action     string    `json:"action"`
+		Resource   string    `json:"resource"`
+		Success    bool      `json:"success"`
+		Reason     string    `json:"reason"`
+		IPAddress  string    `json:"ip_address"`
+		UserAgent  string    `json:"user_agent"`
+		OccurredAt time.Time `json:"occurred_at"`
+	}{
+		ActorID:    row.ActorID,
+		ActorRole:  row.ActorRole,
+		Action:     row.Action,
+		Resource:   row.Resource,
+		Success:    row.Success,
+		Reason:     row.Reason,
+		IPAddress:  row.IPAddress,
+		UserAgent:  row.UserAgent,
+		OccurredAt: row.OccurredAt,
+	})
+}
+
+// ChainBreak describes a point in the audit chain where the stored RowHash
+// no longer matches the recomputed hash of its own contents and PrevHash, or
+// where the row's PrevHash differs from the RowHash of the row before it
+// (Expected is then that RowHash and Actual the stored PrevHash).
+type ChainBreak struct {
+	RowID    uint
+	Expected string
+	Actual   string
+}
+
+// VerifyChain walks AuditEventRow between from and to (inclusive) and
+// reports every row whose RowHash does not match the recomputed hash, plus
+// every row whose PrevHash does not match the RowHash of the row immediately
+// before it in this window. The second check matters as much as the first:
+// deleting a row out of the middle of the chain (rather than truncating the
+// head) leaves every remaining row internally self-consistent with its own
+// PrevHash/RowHash pair, so only recomputing each row's own hash would miss
+// it entirely.
+func (e *HMACChainEmitter) VerifyChain(ctx context.Context, from, to time.Time) ([]ChainBreak, error) {
+	var rows []AuditEventRow
+	if err := e.db.WithContext(ctx).
+		Where("occurred_at BETWEEN ? AND ?", from, to).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load audit chain: %w", err)
+	}
+
+	var breaks []ChainBreak
+	for i, row := range rows {
+		expected, err := e.computeHash(row.PrevHash, row)
+		if err != nil {
+			return nil, err
+		}
+		if expected != row.RowHash {
+			breaks = append(breaks, ChainBreak{RowID: row.ID, Expected: expected, Actual: row.RowHash})
+			continue
+		}
+		if i > 0 && row.PrevHash != rows[i-1].RowHash {
+			breaks = append(breaks, ChainBreak{RowID: row.ID, Expected: rows[i-1].RowHash, Actual: row.PrevHash})
+		}
+	}
+
+	return breaks, nil
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SinkRecorder writes each SessionEvent as a single JSONL line to an
+// io.Writer. It backs the stdout and file sinks; a Kafka sink would satisfy
+// the same Recorder interface with a producer.Write instead.
+type SinkRecorder struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewStdoutRecorder() *SinkRecorder {
+	return &SinkRecorder{w: os.Stdout}
+}
+
+func NewFileRecorder(path string) (*SinkRecorder, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session event log %s: %w", path, err)
+	}
+	return &SinkRecorder{w: f}, nil
+}
+
+func (r *SinkRecorder) Record(ctx context.Context, event SessionEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	line, err := json.Marshal(struct {
+		SessionID  string            `json:"session_id"`
+		ActorID    uint              `json:"actor_id"`
+		Kind       string            `json:"kind"`
+		Resource   string            `json:"resource"`
+		Metadata   map[string]string `json:"metadata,omitempty"`
+		OccurredAt time.Time         `json:"occurred_at"`
+	}{
+		SessionID:  event.SessionID,
+		ActorID:    event.ActorID,
+		Kind:       event.Kind,
+		Resource:   event.Resource,
+		Metadata:   event.Metadata,
+		OccurredAt: event.OccurredAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal session event: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.w.Write(append(line, '\n'))
+	return err
+}
+
+// KafkaRecorder is a placeholder Recorder for a Kafka-backed sink; the
+// producer is injected so this package does not take a hard dependency on a
+// specific Kafka client until one is actually wired in.
+type KafkaRecorder struct {
+	Topic   string
+	Publish func(topic string, payload []byte) error
+}
+
+func (r *KafkaRecorder) Record(ctx context.Context, event SessionEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session event: %w", err)
+	}
+
+	if r.Publish == nil {
+		return fmt.Errorf("kafka recorder has no publish function configured")
+	}
+	return r.Publish(r.Topic, payload)
+}
@@ -0,0 +1,52 @@
+// Package audit separates two concerns that services.AuditService used to
+// funnel through a single LogAction call: durable, tamper-evident audit
+// events (who did what, permitted or not) and high-volume, streamed
+// PHI-access session events (which fields were actually materialized on a
+// given request). The former answers "can we prove this was authorized"
+// for HIPAA §164.312(b); the latter answers "what did this session touch"
+// for forensic replay.
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// AuditEvent is a durable, append-only record of an authorization decision
+// or administrative change. One AuditEvent typically corresponds to one
+// handler call, e.g. "patient.read authorized".
+type AuditEvent struct {
+	ActorID     uint
+	ActorRole   string
+	Action      string
+	Resource    string
+	Success     bool
+	Reason      string
+	IPAddress   string
+	UserAgent   string
+	OccurredAt  time.Time
+}
+
+// SessionEvent is a single PHI-access trace within an authorized session:
+// a patient viewed, a field decrypted, a search executed. Many SessionEvents
+// can follow from a single AuditEvent.
+type SessionEvent struct {
+	SessionID  string
+	ActorID    uint
+	Kind       string // e.g. "patient.viewed", "field.decrypted", "search.executed"
+	Resource   string
+	Metadata   map[string]string
+	OccurredAt time.Time
+}
+
+// Emitter persists AuditEvents to a tamper-evident, hash-chained store.
+type Emitter interface {
+	Emit(ctx context.Context, event AuditEvent) error
+}
+
+// Recorder streams high-volume SessionEvents to a pluggable sink (stdout
+// JSONL, a file, or eventually Kafka) without the durability/ordering
+// guarantees Emitter provides.
+type Recorder interface {
+	Record(ctx context.Context, event SessionEvent) error
+}
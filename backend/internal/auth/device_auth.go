@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"net/http"
+
+	"healthsecure/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DeviceCertMiddleware authenticates clinical devices from the mTLS client
+// certificate negotiated by the TLS handshake, rather than a bearer token.
+// It looks the certificate's CommonName up as a Device.SerialNumber and, on
+// success, populates the same user_id/user_role context keys AuthMiddleware sets
+// for human users so downstream handlers and RequireRole work unmodified.
+//
+// This is intentionally the minimal version: certificate verification
+// against the CA pool is left to net/http's TLSConfig.ClientAuth, and this
+// middleware only maps an already-verified certificate to a Device record. A
+// fuller mTLS mode (OCSP/CRL checks, certificate pinning) is a separate,
+// larger piece of work.
+func DeviceCertMiddleware(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "client certificate required"})
+			c.Abort()
+			return
+		}
+
+		cert := c.Request.TLS.PeerCertificates[0]
+
+		var device models.Device
+		if err := db.Where("serial_number = ?", cert.Subject.CommonName).First(&device).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unknown device certificate"})
+			c.Abort()
+			return
+		}
+
+		if device.IsRevoked() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "device certificate revoked"})
+			c.Abort()
+			return
+		}
+
+		if device.IsExpired() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "device certificate expired"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", device.UserID)
+		c.Set("user_role", string(models.RoleDevice))
+		c.Set("device_id", device.ID)
+		c.Next()
+	}
+}
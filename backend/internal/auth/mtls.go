@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"healthsecure/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MTLSMiddleware authenticates trusted external systems (EHR gateways, lab
+// analyzers) from the mTLS client certificate negotiated by the TLS
+// handshake. Unlike DeviceCertMiddleware, which maps a self-enrolled
+// Device.SerialNumber, this looks the certificate's fingerprint up in
+// device_identities - an admin-managed table bound by certificate
+// fingerprint rather than by CN alone, since a CN or SPIFFE ID can be
+// reused across a certificate's reissues. allowedCNs/allowedSPIFFEIDs are an
+// extra allowlist on top of the CA-verified chain (CA verification itself is
+// left to the http.Server's tls.Config.ClientAuth, same as
+// DeviceCertMiddleware); either list may be empty to skip that check. On
+// success it populates the same user_id/user_role context keys AuthMiddleware
+// sets for human users so downstream handlers and RequireRole work
+// unmodified.
+func MTLSMiddleware(db *gorm.DB, allowedCNs, allowedSPIFFEIDs []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "client certificate required"})
+			c.Abort()
+			return
+		}
+
+		cert := c.Request.TLS.PeerCertificates[0]
+		commonName := cert.Subject.CommonName
+		spiffeID := spiffeIDFromCert(cert)
+
+		if len(allowedCNs) > 0 && !contains(allowedCNs, commonName) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "certificate common name not allowed"})
+			c.Abort()
+			return
+		}
+		if len(allowedSPIFFEIDs) > 0 && !contains(allowedSPIFFEIDs, spiffeID) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "certificate SPIFFE ID not allowed"})
+			c.Abort()
+			return
+		}
+
+		identity, err := resolveDeviceIdentity(db, cert)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unknown device certificate"})
+			c.Abort()
+			return
+		}
+
+		if identity.IsRevoked() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "device certificate revoked"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", identity.UserID)
+		c.Set("user_role", string(models.RoleDevice))
+		c.Set("device_identity_id", identity.ID)
+		c.Next()
+	}
+}
+
+// resolveDeviceIdentity looks up the device_identities row pinned to cert's
+// fingerprint, which is how a certificate is matched regardless of any
+// reissue that keeps the same CN/SPIFFE ID but changes the key material.
+func resolveDeviceIdentity(db *gorm.DB, cert *x509.Certificate) (*models.DeviceIdentity, error) {
+	var identity models.DeviceIdentity
+	err := db.Where("cert_fingerprint = ?", CertFingerprint(cert)).First(&identity).Error
+	return &identity, err
+}
+
+// CertFingerprint is the lowercase hex SHA-256 digest of cert's raw DER
+// bytes, used throughout device_identities as the stable key for a specific
+// issued certificate.
+func CertFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// spiffeIDFromCert returns the certificate's SPIFFE URI SAN (spiffe://...),
+// or "" if it has none.
+func spiffeIDFromCert(cert *x509.Certificate) string {
+	for _, uri := range cert.URIs {
+		if strings.HasPrefix(uri.String(), "spiffe://") {
+			return uri.String()
+		}
+	}
+	return ""
+}
+
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
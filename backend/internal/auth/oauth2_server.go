@@ -0,0 +1,319 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"healthsecure/configs"
+	"healthsecure/internal/database"
+	"healthsecure/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// OAuth2Server implements the grant types an external clinical integration
+// (EHR client, mobile app, lab system) needs to obtain HealthSecure tokens:
+// authorization_code (with PKCE), refresh_token, and client_credentials.
+type OAuth2Server struct {
+	db         *gorm.DB
+	jwtService *JWTService
+	config     *configs.Config
+}
+
+func NewOAuth2Server(db *gorm.DB, jwtService *JWTService, config *configs.Config) *OAuth2Server {
+	return &OAuth2Server{db: db, jwtService: jwtService, config: config}
+}
+
+// OAuthClaims are the claims embedded in access tokens minted by the OAuth2
+// server. They carry the standard iss/aud/sub/exp/iat set plus a scope claim
+// so RBAC middleware can gate CanAccessPatientData/CanAccessSensitiveData.
+type OAuthClaims struct {
+	jwt.RegisteredClaims
+	Scope    string `json:"scope"`
+	ClientID string `json:"client_id"`
+}
+
+type OAuth2Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Scope        string `json:"scope"`
+}
+
+const (
+	oauthAccessTokenTTL  = 15 * time.Minute
+	oauthRefreshTokenTTL = 30 * 24 * time.Hour
+	oauthAuthCodeTTL     = 2 * time.Minute
+	oauthIssuer          = "healthsecure"
+)
+
+// GenerateAuthorizationCode persists a PKCE-bound authorization_code grant
+// for the given client/user/redirect and returns the opaque code.
+func (s *OAuth2Server) GenerateAuthorizationCode(client *models.OAuthClient, userID uint, redirectURI, scope, codeChallenge, codeChallengeMethod string) (string, error) {
+	if !client.RedirectURIAllowed(redirectURI) {
+		return "", fmt.Errorf("redirect_uri not registered for client")
+	}
+
+	code := uuid.NewString()
+	authCode := &models.OAuthAuthorizationCode{
+		Code:                hashToken(code),
+		ClientID:            client.ClientID,
+		UserID:              userID,
+		RedirectURI:         redirectURI,
+		Scope:               scope,
+		CodeChallenge:       codeChallenge,
+		CodeChallengeMethod: codeChallengeMethod,
+		ExpiresAt:           time.Now().Add(oauthAuthCodeTTL),
+	}
+
+	if err := s.db.Create(authCode).Error; err != nil {
+		return "", fmt.Errorf("failed to persist authorization code: %w", err)
+	}
+
+	return code, nil
+}
+
+// ExchangeAuthorizationCode validates client credentials, the PKCE verifier,
+// and the code itself, then mints a fresh access/refresh token pair.
+func (s *OAuth2Server) ExchangeAuthorizationCode(clientID, clientSecret, code, redirectURI, codeVerifier string) (*OAuth2Tokens, error) {
+	client, err := s.authenticateClient(clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.HasGrantType(models.GrantAuthorizationCode) {
+		return nil, fmt.Errorf("client not authorized for authorization_code grant")
+	}
+
+	var authCode models.OAuthAuthorizationCode
+	if err := s.db.Where("code = ? AND client_id = ?", hashToken(code), clientID).First(&authCode).Error; err != nil {
+		return nil, fmt.Errorf("invalid authorization code")
+	}
+	if authCode.Used {
+		return nil, fmt.Errorf("authorization code already used")
+	}
+	if authCode.IsExpired() {
+		return nil, fmt.Errorf("authorization code expired")
+	}
+	if authCode.RedirectURI != redirectURI {
+		return nil, fmt.Errorf("redirect_uri mismatch")
+	}
+	if !verifyPKCE(codeVerifier, authCode.CodeChallenge, authCode.CodeChallengeMethod) {
+		return nil, fmt.Errorf("invalid code_verifier")
+	}
+
+	if err := s.db.Model(&authCode).Update("used", true).Error; err != nil {
+		return nil, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+
+	return s.issueTokens(clientID, authCode.Scope, fmt.Sprintf("%d", authCode.UserID))
+}
+
+// ClientCredentialsGrant issues a token scoped to the client itself, with no
+// associated user (machine-to-machine integrations such as lab feeds).
+func (s *OAuth2Server) ClientCredentialsGrant(clientID, clientSecret, scope string) (*OAuth2Tokens, error) {
+	client, err := s.authenticateClient(clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.HasGrantType(models.GrantClientCredentials) {
+		return nil, fmt.Errorf("client not authorized for client_credentials grant")
+	}
+
+	grantedScope, err := s.restrictScope(client, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.issueTokens(clientID, grantedScope, "client:"+clientID)
+	if err != nil {
+		return nil, err
+	}
+	tokens.RefreshToken = "" // client_credentials grants do not issue refresh tokens
+	return tokens, nil
+}
+
+// RefreshGrant mints a fresh JTI for the access token and blacklists the
+// previous one via the existing JWTService.BlacklistToken path.
+func (s *OAuth2Server) RefreshGrant(clientID, clientSecret, refreshToken string) (*OAuth2Tokens, error) {
+	client, err := s.authenticateClient(clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.HasGrantType(models.GrantRefreshToken) {
+		return nil, fmt.Errorf("client not authorized for refresh_token grant")
+	}
+
+	claims, err := s.parseToken(refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refresh token: %w", err)
+	}
+	if claims.ClientID != clientID {
+		return nil, fmt.Errorf("refresh token was not issued to this client")
+	}
+
+	if s.jwtService != nil {
+		_ = s.jwtService.BlacklistToken(refreshToken)
+	}
+
+	return s.issueTokens(clientID, claims.Scope, claims.Subject)
+}
+
+type IntrospectionResult struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// IntrospectToken reports whether a token is currently active and, when it
+// is, surfaces the scope so callers can gate on insufficient scope.
+func (s *OAuth2Server) IntrospectToken(token string) (*IntrospectionResult, error) {
+	claims, err := s.parseToken(token)
+	if err != nil {
+		return &IntrospectionResult{Active: false}, nil
+	}
+
+	return &IntrospectionResult{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		Subject:   claims.Subject,
+		Issuer:    claims.Issuer,
+		ExpiresAt: claims.ExpiresAt.Unix(),
+	}, nil
+}
+
+func (s *OAuth2Server) issueTokens(clientID, scope, subject string) (*OAuth2Tokens, error) {
+	now := time.Now()
+	access := OAuthClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    oauthIssuer,
+			Audience:  jwt.ClaimStrings{clientID},
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(oauthAccessTokenTTL)),
+		},
+		Scope:    scope,
+		ClientID: clientID,
+	}
+
+	accessToken, err := s.signClaims(access)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	refresh := access
+	refresh.ID = uuid.NewString()
+	refresh.ExpiresAt = jwt.NewNumericDate(now.Add(oauthRefreshTokenTTL))
+
+	refreshToken, err := s.signClaims(refresh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign refresh token: %w", err)
+	}
+
+	return &OAuth2Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(oauthAccessTokenTTL.Seconds()),
+		Scope:        scope,
+	}, nil
+}
+
+func (s *OAuth2Server) signClaims(claims OAuthClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(s.config.JWT.Secret))
+}
+
+func (s *OAuth2Server) parseToken(tokenString string) (*OAuthClaims, error) {
+	claims := &OAuthClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(s.config.JWT.Secret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	if s.isBlacklisted(tokenString) {
+		return nil, fmt.Errorf("token has been revoked")
+	}
+
+	return claims, nil
+}
+
+// isBlacklisted reports whether tokenString has already been rotated out via
+// BlacklistToken. RefreshGrant blacklists every refresh token it rotates
+// away from, but that's only load-bearing if parseToken (the only check
+// RefreshGrant runs against an incoming refresh token) actually honors it -
+// otherwise a stolen or already-rotated refresh token could be replayed
+// indefinitely until it naturally expires.
+func (s *OAuth2Server) isBlacklisted(tokenString string) bool {
+	var count int64
+	s.db.Model(&database.BlacklistedToken{}).Where("token_hash = ?", hashToken(tokenString)).Count(&count)
+	return count > 0
+}
+
+func (s *OAuth2Server) authenticateClient(clientID, clientSecret string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	if err := s.db.Where("client_id = ? AND active = ?", clientID, true).First(&client).Error; err != nil {
+		return nil, fmt.Errorf("invalid client credentials")
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(client.ClientSecretHash), []byte(clientSecret)) != nil {
+		return nil, fmt.Errorf("invalid client credentials")
+	}
+
+	return &client, nil
+}
+
+func (s *OAuth2Server) restrictScope(client *models.OAuthClient, requestedScope string) (string, error) {
+	if requestedScope == "" {
+		return client.AllowedScopes, nil
+	}
+
+	var granted []string
+	for _, scope := range strings.Fields(requestedScope) {
+		if !client.HasScope(scope) {
+			return "", fmt.Errorf("client not allowed scope %q", scope)
+		}
+		granted = append(granted, scope)
+	}
+
+	return strings.Join(granted, " "), nil
+}
+
+// verifyPKCE checks the code_verifier against the stored challenge for the
+// "S256" and "plain" methods defined in RFC 7636.
+func verifyPKCE(verifier, challenge, method string) bool {
+	if challenge == "" {
+		// Clients that did not send a challenge are not held to PKCE.
+		return true
+	}
+
+	switch method {
+	case "S256", "":
+		sum := sha256.Sum256([]byte(verifier))
+		computed := base64.RawURLEncoding.EncodeToString(sum[:])
+		return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+	case "plain":
+		return subtle.ConstantTimeCompare([]byte(verifier), []byte(challenge)) == 1
+	default:
+		return false
+	}
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
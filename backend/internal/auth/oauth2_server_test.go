@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"healthsecure/configs"
+	"healthsecure/internal/database"
+	"healthsecure/internal/models"
+)
+
+func setupOAuth2Server(t *testing.T) (*OAuth2Server, *configs.Config) {
+	config := &configs.Config{
+		Database: configs.DatabaseConfig{
+			Host:     "localhost",
+			Port:     3306,
+			Name:     "test_db",
+			User:     "test",
+			Password: "",
+			TLSMode:  "preferred",
+		},
+		JWT: configs.JWTConfig{
+			Secret:              "test-secret-key-for-testing-minimum-32-chars",
+			Expires:             15 * time.Minute,
+			RefreshTokenExpires: 24 * time.Hour,
+		},
+		Security: configs.SecurityConfig{
+			BCryptCost: 10,
+		},
+		App: configs.AppConfig{
+			Environment: "test",
+		},
+	}
+
+	os.Setenv("SKIP_MIGRATIONS", "false")
+	require.NoError(t, database.Initialize(config))
+
+	jwtService := NewJWTService(config)
+	return NewOAuth2Server(database.GetDB(), jwtService, config), config
+}
+
+func createTestOAuthClient(t *testing.T, secret string, grants []models.OAuthGrantType, scopes, redirectURIs string) *models.OAuthClient {
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	require.NoError(t, err)
+
+	grantStr := ""
+	for i, g := range grants {
+		if i > 0 {
+			grantStr += " "
+		}
+		grantStr += string(g)
+	}
+
+	client := &models.OAuthClient{
+		ClientID:          "client-" + time.Now().Format("150405.000000000"),
+		ClientSecretHash:  string(hash),
+		Name:              "Test Client",
+		RedirectURIs:      redirectURIs,
+		AllowedScopes:     scopes,
+		AllowedGrantTypes: grantStr,
+		Active:            true,
+	}
+	require.NoError(t, database.GetDB().Create(client).Error)
+	return client
+}
+
+func TestOAuth2Server_ClientCredentialsGrant(t *testing.T) {
+	server, _ := setupOAuth2Server(t)
+	defer database.Close()
+
+	client := createTestOAuthClient(t, "s3cret", []models.OAuthGrantType{models.GrantClientCredentials}, "patient.read patient.write", "")
+
+	t.Run("Success", func(t *testing.T) {
+		tokens, err := server.ClientCredentialsGrant(client.ClientID, "s3cret", "patient.read")
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.Empty(t, tokens.RefreshToken)
+		assert.Equal(t, "patient.read", tokens.Scope)
+	})
+
+	t.Run("InvalidClientSecret", func(t *testing.T) {
+		_, err := server.ClientCredentialsGrant(client.ClientID, "wrong-secret", "patient.read")
+		assert.Error(t, err)
+	})
+
+	t.Run("ScopeNotAllowed", func(t *testing.T) {
+		_, err := server.ClientCredentialsGrant(client.ClientID, "s3cret", "patient.sensitive.read")
+		assert.Error(t, err)
+	})
+}
+
+func TestOAuth2Server_AuthorizationCodeGrant(t *testing.T) {
+	server, _ := setupOAuth2Server(t)
+	defer database.Close()
+
+	client := createTestOAuthClient(t, "s3cret", []models.OAuthGrantType{models.GrantAuthorizationCode}, "patient.read", "https://ehr.example.com/callback")
+
+	user := &models.User{Email: "oauth-user@example.com", Role: models.RoleDoctor, Name: "Dr. OAuth", Active: true}
+	require.NoError(t, database.GetDB().Create(user).Error)
+
+	verifier := "a-sufficiently-long-code-verifier-1234567890"
+	challenge := pkceS256Challenge(verifier)
+
+	t.Run("Success", func(t *testing.T) {
+		code, err := server.GenerateAuthorizationCode(client, user.ID, "https://ehr.example.com/callback", "patient.read", challenge, "S256")
+		require.NoError(t, err)
+
+		tokens, err := server.ExchangeAuthorizationCode(client.ClientID, "s3cret", code, "https://ehr.example.com/callback", verifier)
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEmpty(t, tokens.RefreshToken)
+	})
+
+	t.Run("CodeAlreadyUsed", func(t *testing.T) {
+		code, err := server.GenerateAuthorizationCode(client, user.ID, "https://ehr.example.com/callback", "patient.read", challenge, "S256")
+		require.NoError(t, err)
+
+		_, err = server.ExchangeAuthorizationCode(client.ClientID, "s3cret", code, "https://ehr.example.com/callback", verifier)
+		require.NoError(t, err)
+
+		_, err = server.ExchangeAuthorizationCode(client.ClientID, "s3cret", code, "https://ehr.example.com/callback", verifier)
+		assert.Error(t, err)
+	})
+
+	t.Run("ExpiredCode", func(t *testing.T) {
+		code, err := server.GenerateAuthorizationCode(client, user.ID, "https://ehr.example.com/callback", "patient.read", challenge, "S256")
+		require.NoError(t, err)
+
+		database.GetDB().Model(&models.OAuthAuthorizationCode{}).
+			Where("code = ?", hashToken(code)).
+			Update("expires_at", time.Now().Add(-time.Minute))
+
+		_, err = server.ExchangeAuthorizationCode(client.ClientID, "s3cret", code, "https://ehr.example.com/callback", verifier)
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidCodeVerifier", func(t *testing.T) {
+		code, err := server.GenerateAuthorizationCode(client, user.ID, "https://ehr.example.com/callback", "patient.read", challenge, "S256")
+		require.NoError(t, err)
+
+		_, err = server.ExchangeAuthorizationCode(client.ClientID, "s3cret", code, "https://ehr.example.com/callback", "wrong-verifier")
+		assert.Error(t, err)
+	})
+}
+
+func TestOAuth2Server_RefreshGrant(t *testing.T) {
+	server, _ := setupOAuth2Server(t)
+	defer database.Close()
+
+	client := createTestOAuthClient(t, "s3cret", []models.OAuthGrantType{models.GrantAuthorizationCode, models.GrantRefreshToken}, "patient.read", "https://ehr.example.com/callback")
+
+	user := &models.User{Email: "refresh-user@example.com", Role: models.RoleDoctor, Name: "Dr. Refresh", Active: true}
+	require.NoError(t, database.GetDB().Create(user).Error)
+
+	verifier := "a-sufficiently-long-code-verifier-1234567890"
+	challenge := pkceS256Challenge(verifier)
+
+	issue := func() *OAuth2Tokens {
+		code, err := server.GenerateAuthorizationCode(client, user.ID, "https://ehr.example.com/callback", "patient.read", challenge, "S256")
+		require.NoError(t, err)
+		tokens, err := server.ExchangeAuthorizationCode(client.ClientID, "s3cret", code, "https://ehr.example.com/callback", verifier)
+		require.NoError(t, err)
+		return tokens
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		original := issue()
+
+		rotated, err := server.RefreshGrant(client.ClientID, "s3cret", original.RefreshToken)
+		require.NoError(t, err)
+		assert.NotEmpty(t, rotated.AccessToken)
+		assert.NotEmpty(t, rotated.RefreshToken)
+	})
+
+	t.Run("RotatedRefreshTokenCannotBeReplayed", func(t *testing.T) {
+		original := issue()
+
+		_, err := server.RefreshGrant(client.ClientID, "s3cret", original.RefreshToken)
+		require.NoError(t, err)
+
+		// The refresh token just rotated away from must be rejected, not
+		// accepted again - otherwise a stolen refresh token can be replayed
+		// indefinitely even after its legitimate owner has already rotated it.
+		_, err = server.RefreshGrant(client.ClientID, "s3cret", original.RefreshToken)
+		assert.Error(t, err)
+	})
+}
+
+func TestOAuth2Server_IntrospectToken(t *testing.T) {
+	server, _ := setupOAuth2Server(t)
+	defer database.Close()
+
+	client := createTestOAuthClient(t, "s3cret", []models.OAuthGrantType{models.GrantClientCredentials}, "patient.read", "")
+
+	tokens, err := server.ClientCredentialsGrant(client.ClientID, "s3cret", "patient.read")
+	require.NoError(t, err)
+
+	t.Run("ActiveToken", func(t *testing.T) {
+		result, err := server.IntrospectToken(tokens.AccessToken)
+		require.NoError(t, err)
+		assert.True(t, result.Active)
+		assert.Equal(t, "patient.read", result.Scope)
+	})
+
+	t.Run("InsufficientScope", func(t *testing.T) {
+		result, err := server.IntrospectToken(tokens.AccessToken)
+		require.NoError(t, err)
+		assert.True(t, result.Active)
+		// Downstream RBAC middleware gates on scope membership; this token
+		// was only granted patient.read, so patient.sensitive.read must fail.
+		assert.NotContains(t, result.Scope, "patient.sensitive.read")
+	})
+
+	t.Run("GarbageToken", func(t *testing.T) {
+		result, err := server.IntrospectToken("not-a-real-token")
+		require.NoError(t, err)
+		assert.False(t, result.Active)
+	})
+}
+
+func pkceS256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"healthsecure/internal/models"
+)
+
+// UserInfo is the normalized identity an SSOProvider returns after a
+// successful code exchange, regardless of which IdP issued it.
+type UserInfo struct {
+	Subject string
+	Email   string
+	Name    string
+	Groups  []string
+}
+
+// SSOProvider is implemented by every identity provider HealthSecure can
+// accept logins from. AuthCodeURL starts the authorization_code flow;
+// Exchange trades the resulting code for a normalized UserInfo.
+type SSOProvider interface {
+	Name() string
+	AuthCodeURL(state string) string
+	Exchange(code string) (UserInfo, error)
+}
+
+// RoleMappingRule maps an IdP group claim to a HealthSecure role. Rules are
+// evaluated in order; the first whose GroupPattern appears (case-insensitive
+// substring match) among the user's groups wins.
+type RoleMappingRule struct {
+	GroupPattern string
+	Role         models.UserRole
+}
+
+// MapRole resolves the HealthSecure role for a set of provider group claims.
+// It returns false if no rule matched, so callers can refuse to provision an
+// account rather than default to an overly-privileged role.
+func MapRole(groups []string, rules []RoleMappingRule) (models.UserRole, bool) {
+	for _, rule := range rules {
+		for _, group := range groups {
+			if strings.Contains(strings.ToLower(group), strings.ToLower(rule.GroupPattern)) {
+				return rule.Role, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ProviderConfig describes one configured identity provider. Google,
+// Microsoft, and Okta are well-known endpoint presets over the same fields a
+// hospital's own OIDC provider would supply.
+type ProviderConfig struct {
+	Name         string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	EmailClaim   string // defaults to "email"
+	GroupsClaim  string // defaults to "groups"
+}
+
+// oidcProvider is a config-driven SSOProvider covering any authorization-code
+// flow OIDC provider, including the generic-OIDC case the request calls for.
+type oidcProvider struct {
+	cfg    ProviderConfig
+	client *http.Client
+}
+
+// NewOIDCProvider builds a provider purely from config — this is the
+// "generic OIDC" implementation the request asks for, and also backs the
+// Google/Microsoft/Okta presets below.
+func NewOIDCProvider(cfg ProviderConfig) SSOProvider {
+	if cfg.EmailClaim == "" {
+		cfg.EmailClaim = "email"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	return &oidcProvider{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// NewGoogleProvider preconfigures Google's well-known OAuth2/OIDC endpoints.
+func NewGoogleProvider(cfg ProviderConfig) SSOProvider {
+	cfg.Name = "google"
+	cfg.AuthURL = "https://accounts.google.com/o/oauth2/v2/auth"
+	cfg.TokenURL = "https://oauth2.googleapis.com/token"
+	cfg.UserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return NewOIDCProvider(cfg)
+}
+
+// NewMicrosoftProvider preconfigures Microsoft Entra ID's v2.0 endpoints.
+func NewMicrosoftProvider(cfg ProviderConfig) SSOProvider {
+	cfg.Name = "microsoft"
+	cfg.AuthURL = "https://login.microsoftonline.com/common/oauth2/v2.0/authorize"
+	cfg.TokenURL = "https://login.microsoftonline.com/common/oauth2/v2.0/token"
+	cfg.UserInfoURL = "https://graph.microsoft.com/oidc/userinfo"
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return NewOIDCProvider(cfg)
+}
+
+// NewOktaProvider preconfigures Okta's endpoints under the tenant's issuer.
+func NewOktaProvider(cfg ProviderConfig, issuer string) SSOProvider {
+	cfg.Name = "okta"
+	cfg.AuthURL = issuer + "/v1/authorize"
+	cfg.TokenURL = issuer + "/v1/token"
+	cfg.UserInfoURL = issuer + "/v1/userinfo"
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile", "groups"}
+	}
+	return NewOIDCProvider(cfg)
+}
+
+func (p *oidcProvider) Name() string {
+	return p.cfg.Name
+}
+
+func (p *oidcProvider) AuthCodeURL(state string) string {
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	return p.cfg.AuthURL + "?" + q.Encode()
+}
+
+func (p *oidcProvider) Exchange(code string) (UserInfo, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("client_secret", p.cfg.ClientSecret)
+
+	resp, err := p.client.PostForm(p.cfg.TokenURL, form)
+	if err != nil {
+		return UserInfo{}, fmt.Errorf("token exchange request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return UserInfo{}, fmt.Errorf("token exchange failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return UserInfo{}, fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, p.cfg.UserInfoURL, nil)
+	if err != nil {
+		return UserInfo{}, fmt.Errorf("failed to build userinfo request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+tokenResp.AccessToken)
+
+	userResp, err := p.client.Do(req)
+	if err != nil {
+		return UserInfo{}, fmt.Errorf("userinfo request failed: %w", err)
+	}
+	defer userResp.Body.Close()
+
+	var claims map[string]interface{}
+	if err := json.NewDecoder(userResp.Body).Decode(&claims); err != nil {
+		return UserInfo{}, fmt.Errorf("failed to decode userinfo response: %w", err)
+	}
+
+	info := UserInfo{
+		Subject: fmt.Sprintf("%v", claims["sub"]),
+		Email:   fmt.Sprintf("%v", claims[p.cfg.EmailClaim]),
+		Name:    fmt.Sprintf("%v", claims["name"]),
+	}
+
+	if raw, ok := claims[p.cfg.GroupsClaim].([]interface{}); ok {
+		for _, g := range raw {
+			info.Groups = append(info.Groups, fmt.Sprintf("%v", g))
+		}
+	}
+
+	return info, nil
+}
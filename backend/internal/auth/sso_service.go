@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"healthsecure/configs"
+	"healthsecure/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ssoStateTTL is how long a state value generated by GenerateAuthURL stays
+// valid for HandleCallback to redeem.
+const ssoStateTTL = 10 * time.Minute
+
+// ssoState is one in-flight authorization_code flow: which provider it was
+// started for, so HandleCallback can reject a state replayed against the
+// wrong provider, and when it expires.
+type ssoState struct {
+	provider  string
+	expiresAt time.Time
+}
+
+// ssoStateStore is the server-side half of the OAuth state CSRF defense
+// (RFC 6749 §10.12): GenerateAuthURL registers the state it hands to the
+// IdP here, and HandleCallback must redeem the exact same, unused value
+// before a code is ever exchanged - so an attacker who starts their own SSO
+// flow and lures a victim into hitting the resulting callback URL can't
+// bind the victim's session to the attacker's identity, since the state the
+// attacker's flow registered was already consumed (or never reached the
+// victim's browser in the first place; see AuthHandler.SSOLogin's cookie).
+type ssoStateStore struct {
+	mu     sync.Mutex
+	states map[string]ssoState
+}
+
+func newSSOStateStore() *ssoStateStore {
+	return &ssoStateStore{states: make(map[string]ssoState)}
+}
+
+func (s *ssoStateStore) put(state, provider string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state] = ssoState{provider: provider, expiresAt: time.Now().Add(ssoStateTTL)}
+}
+
+// take redeems state for one-time use: a replayed or unknown state, a state
+// issued for a different provider, or an expired one are all rejected.
+func (s *ssoStateStore) take(state, provider string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.states[state]
+	delete(s.states, state)
+	if !ok {
+		return false
+	}
+	return entry.provider == provider && time.Now().Before(entry.expiresAt)
+}
+
+// SSOTokens mirrors the shape UserService.Login returns, so AuthHandler can
+// hand an SSO login result back to clients the same way it hands back a
+// password login.
+type SSOTokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+	User         *models.User
+}
+
+// OAuthService is a registry of configured SSO identity providers. It
+// replaces the single-provider shim: each provider is looked up by name, and
+// a successful callback finds-or-creates the HealthSecure User and links the
+// provider identity to it via UserIdentity, so the same clinician can sign
+// in through Google today and hospital Okta tomorrow.
+type OAuthService struct {
+	db         *gorm.DB
+	jwtService *JWTService
+	providers  map[string]SSOProvider
+	roleRules  []RoleMappingRule
+	states     *ssoStateStore
+}
+
+// NewOAuthService builds the provider registry from config.SSO. Providers
+// with no client ID configured are skipped, so an unconfigured deployment
+// simply reports OAuth as disabled rather than erroring.
+func NewOAuthService(config *configs.Config, db *gorm.DB, jwtService *JWTService) *OAuthService {
+	s := &OAuthService{
+		db:         db,
+		jwtService: jwtService,
+		providers:  make(map[string]SSOProvider),
+		roleRules:  config.SSO.RoleRules(),
+		states:     newSSOStateStore(),
+	}
+
+	for _, p := range config.SSO.Providers {
+		if p.ClientID == "" {
+			continue
+		}
+		s.Register(buildProvider(p))
+	}
+
+	return s
+}
+
+// buildProvider constructs the concrete SSOProvider for a configured entry,
+// dispatching to the well-known presets or falling back to the fully
+// config-driven generic OIDC provider.
+func buildProvider(cfg configs.SSOProviderConfig) SSOProvider {
+	pc := ProviderConfig{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+		EmailClaim:   cfg.EmailClaim,
+		GroupsClaim:  cfg.RoleClaim,
+	}
+
+	switch cfg.Type {
+	case "google":
+		return NewGoogleProvider(pc)
+	case "microsoft":
+		return NewMicrosoftProvider(pc)
+	case "okta":
+		return NewOktaProvider(pc, cfg.Issuer)
+	default:
+		pc.Name = cfg.Name
+		pc.AuthURL = cfg.AuthURL
+		pc.TokenURL = cfg.TokenURL
+		pc.UserInfoURL = cfg.UserInfoURL
+		return NewOIDCProvider(pc)
+	}
+}
+
+// Register adds (or replaces) a provider in the registry. Exported so tests
+// can inject a mock provider without going through config.
+func (s *OAuthService) Register(provider SSOProvider) {
+	s.providers[provider.Name()] = provider
+}
+
+func (s *OAuthService) IsConfigured() bool {
+	return len(s.providers) > 0
+}
+
+func (s *OAuthService) GetSupportedProviders() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// GenerateAuthURL starts the authorization_code flow for the named provider.
+// It returns the state alongside the URL so the caller (AuthHandler.SSOLogin)
+// can bind it to the initiating browser via a short-lived cookie - state is
+// also registered server-side so HandleCallback can reject anything that
+// isn't an exact, unused, same-provider match.
+func (s *OAuthService) GenerateAuthURL(providerName string) (authURL, state string, err error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported SSO provider: %s", providerName)
+	}
+
+	state, err = randomState()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	s.states.put(state, providerName)
+
+	return provider.AuthCodeURL(state), state, nil
+}
+
+// HandleCallback exchanges the authorization code for the named provider,
+// maps the returned group claims to a HealthSecure role, and finds-or-links
+// the HealthSecure User the identity belongs to before minting tokens for it.
+// state must be the exact value GenerateAuthURL issued for providerName - see
+// ssoStateStore for why this is what keeps the flow from being hijacked as a
+// login CSRF.
+func (s *OAuthService) HandleCallback(providerName, code, state string) (*SSOTokens, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, fmt.Errorf("unsupported SSO provider: %s", providerName)
+	}
+
+	if !s.states.take(state, providerName) {
+		return nil, fmt.Errorf("invalid or expired OAuth state")
+	}
+
+	info, err := provider.Exchange(code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code with %s: %w", providerName, err)
+	}
+
+	role, matched := MapRole(info.Groups, s.roleRules)
+	if !matched {
+		return nil, fmt.Errorf("no role mapping configured for %s groups %v", providerName, info.Groups)
+	}
+
+	user, err := s.findOrLinkUser(providerName, info, role)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.jwtService.GenerateTokens(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	return &SSOTokens{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+		User:         user,
+	}, nil
+}
+
+func (s *OAuthService) findOrLinkUser(providerName string, info UserInfo, role models.UserRole) (*models.User, error) {
+	var identity models.UserIdentity
+	err := s.db.Where("provider = ? AND subject = ?", providerName, info.Subject).First(&identity).Error
+
+	if err == nil {
+		var user models.User
+		if err := s.db.First(&user, identity.UserID).Error; err != nil {
+			return nil, fmt.Errorf("linked user %d not found: %w", identity.UserID, err)
+		}
+		return &user, nil
+	}
+
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to look up identity: %w", err)
+	}
+
+	var user models.User
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		lookupErr := tx.Where("email = ?", info.Email).First(&user).Error
+		if lookupErr == gorm.ErrRecordNotFound {
+			user = models.User{
+				Email:  info.Email,
+				Name:   info.Name,
+				Role:   role,
+				Active: true,
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return fmt.Errorf("failed to provision user from %s SSO: %w", providerName, err)
+			}
+		} else if lookupErr != nil {
+			return fmt.Errorf("failed to look up user by email: %w", lookupErr)
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:   user.ID,
+			Provider: providerName,
+			Subject:  info.Subject,
+			LinkedAt: time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func randomState() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"healthsecure/configs"
+	"healthsecure/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Authentication methods references, embedded in the `amr` claim.
+const (
+	AMRPassword = "pwd"
+	AMRTOTP     = "totp"
+	AMRWebAuthn = "webauthn"
+)
+
+// Authentication context class references, embedded in the `acr` claim.
+const (
+	ACRPassword = "urn:hs:acr:pwd" // password only
+	ACRMFA      = "urn:hs:acr:mfa" // password + a second factor
+)
+
+// StepUpClaims is issued after a user clears an MFA challenge and records
+// which methods were used (amr) and the resulting assurance level (acr), so
+// routes gating PHI can require a minimum level via RequireACR.
+type StepUpClaims struct {
+	jwt.RegisteredClaims
+	UserID uint            `json:"uid"`
+	Role   models.UserRole `json:"role"`
+	AMR    []string        `json:"amr"`
+	ACR    string          `json:"acr"`
+}
+
+const stepUpTokenTTL = 10 * time.Minute
+
+// GenerateStepUpToken mints a short-lived assertion carrying the amr/acr
+// claims for the given authentication methods. AuthHandler attaches this to
+// the login response once a TOTP or WebAuthn challenge has been satisfied.
+func GenerateStepUpToken(config *configs.Config, user *models.User, amr []string) (string, error) {
+	now := time.Now()
+	claims := StepUpClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    oauthIssuer,
+			Subject:   fmt.Sprintf("%d", user.ID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(stepUpTokenTTL)),
+		},
+		UserID: user.ID,
+		Role:   user.Role,
+		AMR:    amr,
+		ACR:    acrForAMR(amr),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(config.JWT.Secret))
+}
+
+func ParseStepUpToken(config *configs.Config, tokenString string) (*StepUpClaims, error) {
+	claims := &StepUpClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(config.JWT.Secret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid step-up token")
+	}
+	return claims, nil
+}
+
+func acrForAMR(amr []string) string {
+	for _, m := range amr {
+		if m == AMRTOTP || m == AMRWebAuthn {
+			return ACRMFA
+		}
+	}
+	return ACRPassword
+}
+
+// RequireACR gates a route behind a minimum authentication context class.
+// Any route touching sensitive fields or the emergency-access flow must use
+// this alongside the regular JWT AuthMiddleware. On failure it returns 401
+// with a WWW-Authenticate header the front end uses to trigger the MFA
+// challenge UI.
+func RequireACR(level string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stepUpToken := ExtractTokenFromHeader(c.GetHeader("X-StepUp-Token"))
+		if stepUpToken == "" {
+			denyStepUp(c, level)
+			return
+		}
+
+		rawConfig, exists := c.Get("config")
+		if !exists {
+			denyStepUp(c, level)
+			return
+		}
+		config, ok := rawConfig.(*configs.Config)
+		if !ok {
+			denyStepUp(c, level)
+			return
+		}
+
+		claims, err := ParseStepUpToken(config, stepUpToken)
+		if err != nil {
+			denyStepUp(c, level)
+			return
+		}
+
+		if claims.UserID != c.GetUint("user_id") {
+			denyStepUp(c, level)
+			return
+		}
+
+		if level == ACRMFA && claims.ACR != ACRMFA {
+			denyStepUp(c, level)
+			return
+		}
+
+		c.Set("amr", claims.AMR)
+		c.Set("acr", claims.ACR)
+		c.Next()
+	}
+}
+
+func denyStepUp(c *gin.Context, level string) {
+	c.Header("WWW-Authenticate", fmt.Sprintf(`Step-Up realm="phi", acr=%q`, level))
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "step-up authentication required"})
+}
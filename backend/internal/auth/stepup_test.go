@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"healthsecure/configs"
+	"healthsecure/internal/models"
+)
+
+func testStepUpConfig() *configs.Config {
+	return &configs.Config{
+		JWT: configs.JWTConfig{
+			Secret:              "test-secret-key-for-testing-minimum-32-chars",
+			Expires:             15 * time.Minute,
+			RefreshTokenExpires: 24 * time.Hour,
+		},
+	}
+}
+
+// TestLoginTwoStageFlow covers the two assurance levels a step-up token can
+// carry: amr=["pwd"] alone gives acr=urn:hs:acr:pwd, and only once a TOTP or
+// WebAuthn factor is added (as MFAHandler does after a successful challenge)
+// does the token carry acr=urn:hs:acr:mfa. The tokens are built with
+// GenerateStepUpToken directly; the tokens AuthHandler.Login issues carry no
+// amr or acr claim.
+func TestLoginTwoStageFlow(t *testing.T) {
+	config := testStepUpConfig()
+	user := &models.User{ID: 1, Email: "dr.smith@hospital.local", Role: models.RoleDoctor, Name: "Dr. Smith"}
+
+	t.Run("StageOnePasswordOnlyIsNotMFA", func(t *testing.T) {
+		token, err := GenerateStepUpToken(config, user, []string{AMRPassword})
+		require.NoError(t, err)
+
+		claims, err := ParseStepUpToken(config, token)
+		require.NoError(t, err)
+		assert.Equal(t, ACRPassword, claims.ACR)
+		assert.Equal(t, []string{AMRPassword}, claims.AMR)
+	})
+
+	t.Run("StageTwoTOTPReachesMFAAssurance", func(t *testing.T) {
+		token, err := GenerateStepUpToken(config, user, []string{AMRPassword, AMRTOTP})
+		require.NoError(t, err)
+
+		claims, err := ParseStepUpToken(config, token)
+		require.NoError(t, err)
+		assert.Equal(t, ACRMFA, claims.ACR)
+	})
+
+	t.Run("StageTwoWebAuthnReachesMFAAssurance", func(t *testing.T) {
+		token, err := GenerateStepUpToken(config, user, []string{AMRPassword, AMRWebAuthn})
+		require.NoError(t, err)
+
+		claims, err := ParseStepUpToken(config, token)
+		require.NoError(t, err)
+		assert.Equal(t, ACRMFA, claims.ACR)
+	})
+
+	t.Run("TamperedTokenRejected", func(t *testing.T) {
+		token, err := GenerateStepUpToken(config, user, []string{AMRPassword, AMRTOTP})
+		require.NoError(t, err)
+
+		_, err = ParseStepUpToken(config, token+"tampered")
+		assert.Error(t, err)
+	})
+}
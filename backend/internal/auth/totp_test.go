@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTP(t *testing.T) {
+	t.Run("GenerateSecret", func(t *testing.T) {
+		secret, err := GenerateTOTPSecret()
+		require.NoError(t, err)
+		assert.NotEmpty(t, secret)
+	})
+
+	t.Run("VerifyValidCode", func(t *testing.T) {
+		secret, err := GenerateTOTPSecret()
+		require.NoError(t, err)
+
+		code := generateTOTPCode(secret, unixStep())
+		assert.True(t, VerifyTOTPCode(secret, code))
+	})
+
+	t.Run("VerifyWithinSkewWindow", func(t *testing.T) {
+		secret, err := GenerateTOTPSecret()
+		require.NoError(t, err)
+
+		previousStep := generateTOTPCode(secret, unixStep()-1)
+		assert.True(t, VerifyTOTPCode(secret, previousStep))
+	})
+
+	t.Run("RejectInvalidCode", func(t *testing.T) {
+		secret, err := GenerateTOTPSecret()
+		require.NoError(t, err)
+
+		assert.False(t, VerifyTOTPCode(secret, "000000"))
+	})
+
+	t.Run("RejectMalformedCode", func(t *testing.T) {
+		secret, err := GenerateTOTPSecret()
+		require.NoError(t, err)
+
+		assert.False(t, VerifyTOTPCode(secret, "123"))
+	})
+}
+
+func unixStep() int64 {
+	return time.Now().Unix() / totpStepSecs
+}
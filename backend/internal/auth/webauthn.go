@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"fmt"
+
+	"healthsecure/configs"
+	"healthsecure/internal/models"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/gorm"
+)
+
+// webAuthnUser adapts models.User to the webauthn.User interface expected by
+// go-webauthn/webauthn.
+type webAuthnUser struct {
+	user        *models.User
+	credentials []models.UserWebAuthnCredential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return []byte(fmt.Sprintf("user:%d", u.user.ID))
+}
+
+func (u *webAuthnUser) WebAuthnName() string { return u.user.Email }
+
+func (u *webAuthnUser) WebAuthnDisplayName() string { return u.user.Name }
+
+func (u *webAuthnUser) WebAuthnIcon() string { return "" }
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	creds := make([]webauthn.Credential, 0, len(u.credentials))
+	for _, c := range u.credentials {
+		creds = append(creds, webauthn.Credential{
+			ID:              []byte(c.CredentialID),
+			PublicKey:       c.PublicKey,
+			AttestationType: c.AttestationType,
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    []byte(c.AAGUID),
+				SignCount: c.SignCount,
+			},
+		})
+	}
+	return creds
+}
+
+// WebAuthnService wraps go-webauthn/webauthn with the user/credential lookup
+// needed to enroll and verify optional step-up authenticators.
+type WebAuthnService struct {
+	webAuthn *webauthn.WebAuthn
+	db       *gorm.DB
+}
+
+func NewWebAuthnService(config *configs.Config, db *gorm.DB) (*WebAuthnService, error) {
+	wa, err := webauthn.New(&webauthn.Config{
+		RPDisplayName: "HealthSecure",
+		RPID:          config.App.Domain,
+		RPOrigins:     []string{config.App.BaseURL},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize webauthn: %w", err)
+	}
+
+	return &WebAuthnService{webAuthn: wa, db: db}, nil
+}
+
+func (s *WebAuthnService) loadUser(user *models.User) (*webAuthnUser, error) {
+	var credentials []models.UserWebAuthnCredential
+	if err := s.db.Where("user_id = ?", user.ID).Find(&credentials).Error; err != nil {
+		return nil, fmt.Errorf("failed to load webauthn credentials: %w", err)
+	}
+	return &webAuthnUser{user: user, credentials: credentials}, nil
+}
+
+// BeginRegistration starts a credential-creation ceremony for step-up MFA
+// enrollment. The returned SessionData must be stashed server-side (keyed by
+// a short-lived cookie) and passed back into FinishRegistration.
+func (s *WebAuthnService) BeginRegistration(user *models.User) (*webauthn.SessionData, interface{}, error) {
+	wu, err := s.loadUser(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options, session, err := s.webAuthn.BeginRegistration(wu)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin webauthn registration: %w", err)
+	}
+	return session, options, nil
+}
+
+// FinishRegistration verifies the attestation and persists the resulting
+// credential on the user_webauthn_credentials table.
+func (s *WebAuthnService) FinishRegistration(user *models.User, session webauthn.SessionData, response interface{}) error {
+	wu, err := s.loadUser(user)
+	if err != nil {
+		return err
+	}
+
+	credential, err := s.webAuthn.CreateCredential(wu, session, response)
+	if err != nil {
+		return fmt.Errorf("failed to verify webauthn attestation: %w", err)
+	}
+
+	record := &models.UserWebAuthnCredential{
+		UserID:          user.ID,
+		CredentialID:    string(credential.ID),
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          string(credential.Authenticator.AAGUID),
+		SignCount:       credential.Authenticator.SignCount,
+	}
+
+	if err := s.db.Create(record).Error; err != nil {
+		return fmt.Errorf("failed to persist webauthn credential: %w", err)
+	}
+
+	return nil
+}
+
+// BeginLogin starts an assertion ceremony against the user's enrolled
+// credentials.
+func (s *WebAuthnService) BeginLogin(user *models.User) (*webauthn.SessionData, interface{}, error) {
+	wu, err := s.loadUser(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options, session, err := s.webAuthn.BeginLogin(wu)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin webauthn login: %w", err)
+	}
+	return session, options, nil
+}
+
+// FinishLogin verifies the assertion and updates the credential's sign
+// counter to detect cloned authenticators.
+func (s *WebAuthnService) FinishLogin(user *models.User, session webauthn.SessionData, response interface{}) error {
+	wu, err := s.loadUser(user)
+	if err != nil {
+		return err
+	}
+
+	credential, err := s.webAuthn.ValidateLogin(wu, session, response)
+	if err != nil {
+		return fmt.Errorf("failed to verify webauthn assertion: %w", err)
+	}
+
+	return s.db.Model(&models.UserWebAuthnCredential{}).
+		Where("credential_id = ?", string(credential.ID)).
+		Update("sign_count", credential.Authenticator.SignCount).Error
+}
@@ -0,0 +1,32 @@
+package chatcommands
+
+import "regexp"
+
+// searchQueryPattern pulls the search term out of "find patient <x>" /
+// "search patient <x>" style messages into the "query" arg.
+var searchQueryPattern = regexp.MustCompile(`(?i)(?:find|search)\s+patient[s]?\s+(.+)`)
+
+// patientIDPattern and reasonPattern pull the break-glass request's required
+// fields out of messages like "emergency, patient: 42, reason: unresponsive".
+var patientIDPattern = regexp.MustCompile(`(?i)patient[:\s]+#?(\d+)`)
+var reasonPattern = regexp.MustCompile(`(?i)reason[:\s]+(.+)`)
+
+// extractArgs does today's regex/keyword argument extraction for a
+// triggering message. It's intentionally a plain function rather than part
+// of the Command interface so it can be swapped for an LLM tool-call schema
+// later without every command needing to change.
+func extractArgs(msg string) map[string]string {
+	args := make(map[string]string)
+
+	if m := searchQueryPattern.FindStringSubmatch(msg); len(m) == 2 {
+		args["query"] = m[1]
+	}
+	if m := patientIDPattern.FindStringSubmatch(msg); len(m) == 2 {
+		args["patient_id"] = m[1]
+	}
+	if m := reasonPattern.FindStringSubmatch(msg); len(m) == 2 {
+		args["reason"] = m[1]
+	}
+
+	return args
+}
@@ -0,0 +1,146 @@
+package chatcommands
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"healthsecure/internal/models"
+)
+
+// DefaultCommands returns the built-in commands that previously lived in
+// ChatHandler.processSpecialCommands, now independently registrable/testable.
+// They match the same phrases and roles as before; emergency_access now also
+// reports the fields it needs (required_fields), and patient_search passes a
+// query argument through when one is given.
+func DefaultCommands() []Command {
+	return []Command{
+		patientSearchCommand{},
+		emergencyAccessCommand{},
+		auditLogsCommand{},
+		createPatientCommand{},
+	}
+}
+
+// DefaultRegistry is the registry ChatHandler dispatches through unless a
+// caller builds its own (e.g. to disable a tool per tenant).
+var DefaultRegistry = buildDefaultRegistry()
+
+func buildDefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, cmd := range DefaultCommands() {
+		r.Register(cmd)
+	}
+	return r
+}
+
+func containsAny(msg string, phrases ...string) bool {
+	lower := strings.ToLower(msg)
+	for _, p := range phrases {
+		if strings.Contains(lower, p) {
+			return true
+		}
+	}
+	return false
+}
+
+type patientSearchCommand struct{}
+
+func (patientSearchCommand) Name() string { return "patient_search" }
+
+func (patientSearchCommand) Match(msg string, _ models.UserRole) bool {
+	return containsAny(msg, "find patient", "search patient")
+}
+
+func (patientSearchCommand) RequiredRoles() []models.UserRole { return nil }
+
+func (patientSearchCommand) Execute(_ context.Context, _ User, args map[string]string) ([]string, map[string]interface{}, error) {
+	data := map[string]interface{}{"search_context": "patient_lookup"}
+	if query, ok := args["query"]; ok {
+		data["query"] = query
+	}
+	return []string{"patient_search"}, data, nil
+}
+
+// GrantRequester is the subset of *services.EmergencyAccessService that
+// emergencyAccessCommand needs, kept as an interface so the command can be
+// unit-tested without a database.
+type GrantRequester interface {
+	RequestAccess(userID, patientID uint, reason string) (*models.EmergencyAccessGrant, error)
+}
+
+// emergencyAccessCommand's zero value (as returned by DefaultCommands) only
+// ever prompts for the required fields; NewEmergencyAccessCommand backs it
+// with a real requester so it can actually open a grant.
+type emergencyAccessCommand struct {
+	requester GrantRequester
+}
+
+// NewEmergencyAccessCommand builds the live "emergency_access" command. A
+// caller (ChatHandler) registers it over the no-op default by name so the
+// rest of the registry is unaffected.
+func NewEmergencyAccessCommand(requester GrantRequester) Command {
+	return emergencyAccessCommand{requester: requester}
+}
+
+func (emergencyAccessCommand) Name() string { return "emergency_access" }
+
+func (emergencyAccessCommand) Match(msg string, _ models.UserRole) bool {
+	return containsAny(msg, "emergency")
+}
+
+func (emergencyAccessCommand) RequiredRoles() []models.UserRole {
+	return []models.UserRole{models.RoleDoctor, models.RoleNurse}
+}
+
+func (c emergencyAccessCommand) Execute(_ context.Context, user User, args map[string]string) ([]string, map[string]interface{}, error) {
+	data := map[string]interface{}{"emergency_context": "access_request"}
+
+	patientIDStr, hasPatient := args["patient_id"]
+	reason, hasReason := args["reason"]
+
+	patientID, parseErr := strconv.ParseUint(patientIDStr, 10, 32)
+	if c.requester == nil || !hasPatient || !hasReason || parseErr != nil {
+		data["required_fields"] = []string{"patient_id", "reason"}
+		return []string{"emergency_access"}, data, nil
+	}
+
+	grant, err := c.requester.RequestAccess(user.ID, uint(patientID), reason)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data["grant_id"] = grant.ID
+	data["expires_at"] = grant.ExpiresAt
+	return []string{"emergency_access"}, data, nil
+}
+
+type auditLogsCommand struct{}
+
+func (auditLogsCommand) Name() string { return "audit_logs" }
+
+func (auditLogsCommand) Match(msg string, _ models.UserRole) bool {
+	return containsAny(msg, "audit", "logs")
+}
+
+func (auditLogsCommand) RequiredRoles() []models.UserRole { return nil }
+
+func (auditLogsCommand) Execute(_ context.Context, _ User, _ map[string]string) ([]string, map[string]interface{}, error) {
+	return []string{"audit_logs"}, map[string]interface{}{"audit_context": "log_access"}, nil
+}
+
+type createPatientCommand struct{}
+
+func (createPatientCommand) Name() string { return "create_patient" }
+
+func (createPatientCommand) Match(msg string, _ models.UserRole) bool {
+	return containsAny(msg, "add patient", "new patient")
+}
+
+func (createPatientCommand) RequiredRoles() []models.UserRole {
+	return []models.UserRole{models.RoleDoctor, models.RoleNurse}
+}
+
+func (createPatientCommand) Execute(_ context.Context, _ User, _ map[string]string) ([]string, map[string]interface{}, error) {
+	return []string{"create_patient"}, map[string]interface{}{"creation_context": "patient_form"}, nil
+}
@@ -0,0 +1,66 @@
+// Package chatcommands replaces ChatHandler's hard-coded
+// if-strings.Contains chain of special-case chat behaviors with a
+// registry of named, independently testable commands. New capabilities
+// register themselves at startup instead of growing another branch in the
+// handler, and the registry can be introspected (GET /chat/commands) or
+// filtered per tenant.
+package chatcommands
+
+import (
+	"context"
+
+	"healthsecure/internal/models"
+)
+
+// User is the subset of request context a Command needs to decide whether
+// it applies and, if so, to execute.
+type User struct {
+	ID   uint
+	Role models.UserRole
+	Name string
+}
+
+// Command is a single chat-triggerable capability. Match and Execute are
+// deliberately separated so the registry can list/gate commands (e.g. for
+// GET /chat/commands) without invoking them.
+type Command interface {
+	// Name is the intent name the registry keys this command under, e.g.
+	// "patient_search".
+	Name() string
+
+	// Match reports whether msg should trigger this command for a user with
+	// the given role. Implementations run purely on the message text today
+	// (regex/keyword matching); an LLM tool-call schema could replace the
+	// matching strategy later without changing this interface.
+	Match(msg string, role models.UserRole) bool
+
+	// RequiredRoles lists the roles allowed to invoke this command. An empty
+	// slice means any authenticated role may invoke it.
+	RequiredRoles() []models.UserRole
+
+	// Execute runs the command for user against args extracted from the
+	// triggering message, returning the chat actions/data to merge into the
+	// ChatResponse.
+	Execute(ctx context.Context, user User, args map[string]string) (actions []string, data map[string]interface{}, err error)
+}
+
+// Info is the read-only view of a registered command returned by
+// GET /chat/commands.
+type Info struct {
+	Name          string            `json:"name"`
+	RequiredRoles []models.UserRole `json:"required_roles,omitempty"`
+}
+
+// hasRole reports whether role appears in allowed, treating an empty
+// allowed list as "any role".
+func hasRole(allowed []models.UserRole, role models.UserRole) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, r := range allowed {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package chatcommands
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"healthsecure/internal/models"
+)
+
+// Registry dispatches a chat message to whichever registered Commands match
+// it and the caller's role, in registration order. It's safe for concurrent
+// use so commands can be registered at startup while requests are already
+// being served.
+type Registry struct {
+	mu       sync.RWMutex
+	commands []Command
+	byName   map[string]Command
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]Command)}
+}
+
+// Register adds cmd to the registry. A later Register with the same Name
+// replaces the earlier one, so callers can override a built-in command by
+// re-registering under its name.
+func (r *Registry) Register(cmd Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byName[cmd.Name()]; !exists {
+		r.commands = append(r.commands, cmd)
+	} else {
+		for i, existing := range r.commands {
+			if existing.Name() == cmd.Name() {
+				r.commands[i] = cmd
+				break
+			}
+		}
+	}
+	r.byName[cmd.Name()] = cmd
+}
+
+// Dispatch runs every registered command whose Match(msg, user.Role) is true
+// and whose RequiredRoles permit user.Role, merging their actions/data in
+// registration order. A command that isn't role-permitted is silently
+// skipped rather than erroring, since an unmatched intent just means the
+// message didn't ask for that capability.
+func (r *Registry) Dispatch(ctx context.Context, msg string, user User) (actions []string, data map[string]interface{}, err error) {
+	r.mu.RLock()
+	commands := make([]Command, len(r.commands))
+	copy(commands, r.commands)
+	r.mu.RUnlock()
+
+	data = make(map[string]interface{})
+
+	for _, cmd := range commands {
+		if !cmd.Match(msg, user.Role) {
+			continue
+		}
+		if !hasRole(cmd.RequiredRoles(), user.Role) {
+			continue
+		}
+
+		cmdActions, cmdData, cmdErr := cmd.Execute(ctx, user, extractArgs(msg))
+		if cmdErr != nil {
+			return actions, data, fmt.Errorf("command %q failed: %w", cmd.Name(), cmdErr)
+		}
+		actions = append(actions, cmdActions...)
+		for k, v := range cmdData {
+			data[k] = v
+		}
+	}
+
+	return actions, data, nil
+}
+
+// List returns every registered command along with the roles allowed to
+// invoke it, in registration order, for GET /chat/commands.
+func (r *Registry) List() []Info {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]Info, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		infos = append(infos, Info{Name: cmd.Name(), RequiredRoles: cmd.RequiredRoles()})
+	}
+	return infos
+}
+
+// ListForRole returns only the commands role is permitted to invoke, for
+// callers that want to show a user their own available tools rather than
+// the full admin listing.
+func (r *Registry) ListForRole(role models.UserRole) []Info {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]Info, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		if hasRole(cmd.RequiredRoles(), role) {
+			infos = append(infos, Info{Name: cmd.Name(), RequiredRoles: cmd.RequiredRoles()})
+		}
+	}
+	return infos
+}
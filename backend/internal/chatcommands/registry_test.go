@@ -0,0 +1,101 @@
+package chatcommands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthsecure/internal/models"
+)
+
+func TestRegistry_DispatchMergesMatchingCommands(t *testing.T) {
+	r := NewRegistry()
+	for _, cmd := range DefaultCommands() {
+		r.Register(cmd)
+	}
+
+	actions, data, err := r.Dispatch(context.Background(), "please find patient jane doe", User{Role: models.RoleNurse})
+	require.NoError(t, err)
+	assert.Contains(t, actions, "patient_search")
+	assert.Equal(t, "jane doe", data["query"])
+}
+
+func TestRegistry_RoleGatingExcludesDisallowedCommands(t *testing.T) {
+	r := NewRegistry()
+	for _, cmd := range DefaultCommands() {
+		r.Register(cmd)
+	}
+
+	t.Run("NurseCanTriggerEmergencyAccess", func(t *testing.T) {
+		actions, _, err := r.Dispatch(context.Background(), "this is an emergency", User{Role: models.RoleNurse})
+		require.NoError(t, err)
+		assert.Contains(t, actions, "emergency_access")
+	})
+
+	t.Run("AdminCannotTriggerEmergencyAccess", func(t *testing.T) {
+		actions, _, err := r.Dispatch(context.Background(), "this is an emergency", User{Role: models.RoleAdmin})
+		require.NoError(t, err)
+		assert.NotContains(t, actions, "emergency_access")
+	})
+}
+
+func TestRegistry_RegisterOverridesExistingCommandByName(t *testing.T) {
+	r := NewRegistry()
+	r.Register(patientSearchCommand{})
+
+	called := false
+	r.Register(fakeCommand{name: "patient_search", onExecute: func() { called = true }})
+
+	_, _, err := r.Dispatch(context.Background(), "find patient john", User{Role: models.RoleDoctor})
+	require.NoError(t, err)
+	assert.True(t, called, "re-registering a command by name should replace the original")
+
+	infos := r.List()
+	require.Len(t, infos, 1, "re-registering by name should not add a duplicate entry")
+}
+
+type fakeCommand struct {
+	name      string
+	onExecute func()
+}
+
+func (f fakeCommand) Name() string                             { return f.name }
+func (f fakeCommand) Match(msg string, _ models.UserRole) bool { return true }
+func (f fakeCommand) RequiredRoles() []models.UserRole         { return nil }
+func (f fakeCommand) Execute(_ context.Context, _ User, _ map[string]string) ([]string, map[string]interface{}, error) {
+	f.onExecute()
+	return nil, nil, nil
+}
+
+func TestRegistry_ListReturnsRolesPerCommand(t *testing.T) {
+	r := NewRegistry()
+	for _, cmd := range DefaultCommands() {
+		r.Register(cmd)
+	}
+
+	infos := r.List()
+	require.Len(t, infos, len(DefaultCommands()))
+
+	var emergency Info
+	for _, info := range infos {
+		if info.Name == "emergency_access" {
+			emergency = info
+		}
+	}
+	assert.ElementsMatch(t, []models.UserRole{models.RoleDoctor, models.RoleNurse}, emergency.RequiredRoles)
+}
+
+func TestRegistry_ListForRoleFiltersByRole(t *testing.T) {
+	r := NewRegistry()
+	for _, cmd := range DefaultCommands() {
+		r.Register(cmd)
+	}
+
+	adminInfos := r.ListForRole(models.RoleAdmin)
+	for _, info := range adminInfos {
+		assert.NotEqual(t, "emergency_access", info.Name)
+		assert.NotEqual(t, "create_patient", info.Name)
+	}
+}
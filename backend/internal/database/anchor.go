@@ -0,0 +1,78 @@
+package database
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AnchorAuditChainHead appends the current security_events chain head to an
+// external append-only store, so that rewriting the chain in the database
+// alone (e.g. by an attacker with write access, or a rogue DBA) isn't enough
+// to erase evidence of tampering - the anchor and the DB have to agree.
+//
+// The default anchor is a local append-only file; set AUDIT_ANCHOR_PATH to
+// point it at a mounted WORM volume (e.g. an S3 Object Lock bucket synced to
+// disk) instead. Set AUDIT_ANCHOR_SIGNING_KEY (a 64-char hex ed25519 private
+// key) to also sign each anchor line, so the anchor file itself can't be
+// silently edited either. Called periodically alongside RunCleanupTasks when
+// AUDIT_ANCHORING_ENABLED=true.
+func AnchorAuditChainHead() error {
+	var head SecurityEvent
+	if err := DB.Order("id DESC").First(&head).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to read security event chain head: %w", err)
+	}
+
+	line := fmt.Sprintf("%s security_events head=%d hash=%s",
+		time.Now().UTC().Format(time.RFC3339), head.ID, head.RowHash)
+
+	if sig, ok := signAnchorLine(line); ok {
+		line += " sig=" + sig
+	}
+
+	path := os.Getenv("AUDIT_ANCHOR_PATH")
+	if path == "" {
+		path = "data/audit_chain_anchor.log"
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit chain anchor: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("failed to append to audit chain anchor: %w", err)
+	}
+
+	log.Printf("Anchored security_events chain head (id=%d) to %s", head.ID, path)
+	return nil
+}
+
+// signAnchorLine ed25519-signs line with AUDIT_ANCHOR_SIGNING_KEY if
+// configured, returning the hex-encoded signature. ok is false when signing
+// isn't configured, in which case the anchor is still append-only but not
+// independently verifiable against the signing key.
+func signAnchorLine(line string) (sig string, ok bool) {
+	hexKey := os.Getenv("AUDIT_ANCHOR_SIGNING_KEY")
+	if hexKey == "" {
+		return "", false
+	}
+
+	key, err := hex.DecodeString(hexKey)
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		log.Printf("Warning: invalid AUDIT_ANCHOR_SIGNING_KEY, skipping anchor signature")
+		return "", false
+	}
+
+	return hex.EncodeToString(ed25519.Sign(ed25519.PrivateKey(key), []byte(line))), true
+}
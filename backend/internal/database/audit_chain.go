@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// securityEventChainKey HMACs the security_events hash chain, the same way
+// internal/audit.HMACChainEmitter keys audit_event_chain - a plain, unkeyed
+// sha256.Sum256(prevHash || row) lets anyone able to write to security_events
+// directly (a compromised app server, a rogue DBA) recompute a valid chain
+// after altering or deleting rows, since no secret is needed to forge
+// RowHash. Loaded once from SECURITY_EVENT_CHAIN_KEY, a 32-byte key
+// hex-encoded to 64 characters, mirroring fieldEncryptionKey's loading
+// pattern in encryption.go.
+var securityEventChainKey []byte
+
+func init() {
+	hexKey := os.Getenv("SECURITY_EVENT_CHAIN_KEY")
+	if hexKey == "" {
+		return
+	}
+	key, err := hex.DecodeString(hexKey)
+	if err != nil || len(key) != 32 {
+		return
+	}
+	securityEventChainKey = key
+}
+
+// securityEventChainMu serializes AppendSecurityEvent calls so that
+// SecurityEvent.BeforeCreate's read-then-write of the chain head (read the
+// current RowHash, then insert a row computed from it) can never interleave
+// across two concurrent callers. Without this, two goroutines emitting a
+// SecurityEvent at the same instant (e.g. two AI-service circuit breaker
+// transitions) can both read the same head, insert concurrently, and fork
+// the chain - which VerifyAuditChain then reports as tampering even though
+// nothing was tampered with.
+var securityEventChainMu sync.Mutex
+
+// AppendSecurityEvent inserts se as the next row in the security_events hash
+// chain. Callers must use this instead of db.Create(se) directly, since a
+// bare Create lets two concurrent callers both read the same chain head
+// before either one inserts.
+func AppendSecurityEvent(db *gorm.DB, se *SecurityEvent) error {
+	securityEventChainMu.Lock()
+	defer securityEventChainMu.Unlock()
+	return db.Create(se).Error
+}
+
+// ChainBreak describes a point in the security_events hash chain where the
+// stored RowHash no longer matches the recomputed hash of its own PrevHash
+// and contents - i.e. the row (or one before it) was altered after the fact -
+// or where the row's PrevHash differs from the RowHash of the row before it
+// (Expected is then that RowHash and Actual the stored PrevHash).
+type ChainBreak struct {
+	Table    string
+	RowID    uint
+	Expected string
+	Actual   string
+}
+
+// computeSecurityEventHash hashes prevHash together with se's own contents
+// (post field-encryption), so the resulting RowHash commits to the entire
+// chain up to and including this row.
+func computeSecurityEventHash(prevHash string, se *SecurityEvent) (string, error) {
+	canonical, err := json.Marshal(struct {
+		EventType   SecurityEventType     `json:"event_type"`
+		Severity    SecurityEventSeverity `json:"severity"`
+		UserID      *uint                 `json:"user_id"`
+		IPAddress   string                `json:"ip_address"`
+		Description string                `json:"description"`
+		Details     string                `json:"details"`
+		CreatedAt   time.Time             `json:"created_at"`
+	}{
+		EventType:   se.EventType,
+		Severity:    se.Severity,
+		UserID:      se.UserID,
+		IPAddress:   se.IPAddress,
+		Description: se.Description,
+		Details:     se.Details,
+		CreatedAt:   se.CreatedAt,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to canonicalize security event: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, securityEventChainKey)
+	mac.Write([]byte(prevHash))
+	mac.Write(canonical)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifyAuditChain walks security_events created between from and to
+// (inclusive) in row order and reports every row whose RowHash no longer
+// matches the recomputed hash of its PrevHash and contents, plus every row
+// whose PrevHash doesn't match the RowHash of the row immediately before it
+// in this window. The second check matters as much as the first: deleting a
+// row out of the middle of the chain (rather than truncating the head)
+// leaves every remaining row internally self-consistent with its own
+// PrevHash/RowHash pair, so only comparing a row against its own stored hash
+// would miss it entirely. An empty result means the chain is intact across
+// that window.
+//
+// audit_event_chain (internal/audit) is a separate, HMAC-keyed chain for a
+// different table and is verified independently via
+// audit.HMACChainEmitter.VerifyChain. models.AuditLog rows are not part of
+// either chain.
+func VerifyAuditChain(ctx context.Context, from, to time.Time) ([]ChainBreak, error) {
+	var rows []SecurityEvent
+	if err := DB.WithContext(ctx).
+		Where("created_at BETWEEN ? AND ?", from, to).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load security event chain: %w", err)
+	}
+
+	var breaks []ChainBreak
+	for i := range rows {
+		expected, err := computeSecurityEventHash(rows[i].PrevHash, &rows[i])
+		if err != nil {
+			return nil, err
+		}
+		if expected != rows[i].RowHash {
+			breaks = append(breaks, ChainBreak{
+				Table:    "security_events",
+				RowID:    rows[i].ID,
+				Expected: expected,
+				Actual:   rows[i].RowHash,
+			})
+			continue
+		}
+		if i > 0 && rows[i].PrevHash != rows[i-1].RowHash {
+			breaks = append(breaks, ChainBreak{
+				Table:    "security_events",
+				RowID:    rows[i].ID,
+				Expected: rows[i-1].RowHash,
+				Actual:   rows[i].PrevHash,
+			})
+		}
+	}
+	return breaks, nil
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver
+)
+
+func setupChainDB(t *testing.T) {
+	// One in-memory database per test, so rows tampered with or deleted by an
+	// earlier test don't show up as breaks in a later one.
+	db, err := gorm.Open(sqlite.Dialector{DriverName: "sqlite", DSN: "file:" + t.Name() + "?mode=memory&cache=shared"}, &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&SecurityEvent{}))
+	DB = db
+}
+
+func TestSecurityEvent_BeforeCreate_ChainsHashes(t *testing.T) {
+	setupChainDB(t)
+
+	require.NoError(t, DB.Create(&SecurityEvent{EventType: SecurityEventSystemAlert, Description: "first"}).Error)
+	require.NoError(t, DB.Create(&SecurityEvent{EventType: SecurityEventSystemAlert, Description: "second"}).Error)
+
+	var rows []SecurityEvent
+	require.NoError(t, DB.Order("id ASC").Find(&rows).Error)
+	require.Len(t, rows, 2)
+
+	assert.Empty(t, rows[0].PrevHash)
+	assert.Equal(t, rows[0].RowHash, rows[1].PrevHash)
+	assert.NotEqual(t, rows[0].RowHash, rows[1].RowHash)
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	setupChainDB(t)
+	ctx := context.Background()
+	start := time.Now().Add(-time.Minute)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, DB.Create(&SecurityEvent{EventType: SecurityEventSystemAlert, Description: "event"}).Error)
+	}
+
+	end := time.Now().Add(time.Minute)
+
+	t.Run("IntactChainHasNoBreaks", func(t *testing.T) {
+		breaks, err := VerifyAuditChain(ctx, start, end)
+		require.NoError(t, err)
+		assert.Empty(t, breaks)
+	})
+
+	t.Run("TamperedRowIsDetected", func(t *testing.T) {
+		require.NoError(t, DB.Model(&SecurityEvent{}).Where("id = ?", 2).Update("description", "tampered").Error)
+
+		breaks, err := VerifyAuditChain(ctx, start, end)
+		require.NoError(t, err)
+		require.Len(t, breaks, 1)
+		assert.Equal(t, uint(2), breaks[0].RowID)
+	})
+}
+
+func TestVerifyAuditChain_DetectsDeletedMiddleRow(t *testing.T) {
+	setupChainDB(t)
+	ctx := context.Background()
+	start := time.Now().Add(-time.Minute)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, DB.Create(&SecurityEvent{EventType: SecurityEventSystemAlert, Description: "event"}).Error)
+	}
+	end := time.Now().Add(time.Minute)
+
+	// Deleting row 2 outright (rather than altering its contents) leaves
+	// row 3's own PrevHash/RowHash pair internally self-consistent - it's
+	// only detectable by comparing row 3's PrevHash against row 1's RowHash.
+	require.NoError(t, DB.Unscoped().Delete(&SecurityEvent{}, 2).Error)
+
+	breaks, err := VerifyAuditChain(ctx, start, end)
+	require.NoError(t, err)
+	require.Len(t, breaks, 1)
+	assert.Equal(t, uint(3), breaks[0].RowID)
+}
+
+// Two AppendSecurityEvent calls racing to read the same chain head must not
+// both succeed in inserting a row built from it - e.g. two AI-service
+// circuit breaker transitions landing at the same instant - or the chain
+// forks and VerifyAuditChain reports the fork as tampering even though
+// nothing was altered.
+func TestAppendSecurityEvent_ConcurrentCallsDoNotForkTheChain(t *testing.T) {
+	setupChainDB(t)
+	ctx := context.Background()
+	start := time.Now().Add(-time.Minute)
+
+	const concurrency = 20
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, AppendSecurityEvent(DB, &SecurityEvent{EventType: SecurityEventSystemAlert, Description: "event"}))
+		}()
+	}
+	wg.Wait()
+	end := time.Now().Add(time.Minute)
+
+	breaks, err := VerifyAuditChain(ctx, start, end)
+	require.NoError(t, err)
+	assert.Empty(t, breaks)
+}
+
+func TestEncryptDecryptField_RoundTrip(t *testing.T) {
+	original := fieldEncryptionKey
+	defer func() { fieldEncryptionKey = original }()
+
+	fieldEncryptionKey = []byte("01234567890123456789012345678901"[:32])
+
+	ciphertext, err := EncryptField("sensitive details")
+	require.NoError(t, err)
+	assert.NotEqual(t, "sensitive details", ciphertext)
+
+	plaintext, err := DecryptField(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "sensitive details", plaintext)
+}
+
+func TestDecryptField_PassesThroughUnencryptedValues(t *testing.T) {
+	original := fieldEncryptionKey
+	defer func() { fieldEncryptionKey = original }()
+
+	fieldEncryptionKey = []byte("01234567890123456789012345678901"[:32])
+
+	plaintext, err := DecryptField("plain legacy value")
+	require.NoError(t, err)
+	assert.Equal(t, "plain legacy value", plaintext)
+}
@@ -1,7 +1,8 @@
 package database
 
 import (
-	"crypto/tls"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -11,20 +12,21 @@ import (
 	"healthsecure/configs"
 	"healthsecure/internal/models"
 
-	"github.com/go-sql-driver/mysql"
-	gorm_mysql "gorm.io/driver/mysql"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
-	_ "modernc.org/sqlite" // Pure Go SQLite driver
 )
 
 var DB *gorm.DB
 
+// activeDialect is the Dialect Initialize resolved the connection through.
+// handleExistingEnumColumns and handleEnumMigration use it to stay
+// dialect-agnostic instead of branching on DB.Dialector.Name() themselves.
+var activeDialect Dialect
+
 // Initialize establishes database connection and runs migrations
 func Initialize(config *configs.Config) error {
 	var err error
-	
+
 	// Configure GORM logger based on environment
 	var gormConfig *gorm.Config
 	if config.IsProduction() {
@@ -37,55 +39,30 @@ func Initialize(config *configs.Config) error {
 		}
 	}
 
-	// Use MySQL for Railway or remote hosts, SQLite for local development
-	if config.Database.Host != "" && config.Database.Host != "localhost" && config.Database.Host != "127.0.0.1" {
-		// Register custom TLS config for Railway MySQL
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: true, // For Railway, skip certificate verification
-		}
-		if err := mysql.RegisterTLSConfig("railway", tlsConfig); err != nil {
-			log.Printf("Failed to register TLS config: %v", err)
-		}
-		
-		// Update DSN to use custom TLS config
-		dsn := config.GetDatabaseDSN()
-		if config.Database.TLSMode != "" && config.Database.TLSMode != "preferred" {
-			// Replace the tls parameter with our custom config
-			dsn = strings.Replace(dsn, "&tls="+config.Database.TLSMode, "&tls=railway", 1)
-		} else {
-			// Add TLS if not present
-			dsn += "&tls=railway"
-		}
-		
-		DB, err = gorm.Open(gorm_mysql.Open(dsn), gormConfig)
-		if err != nil {
-			log.Printf("MySQL connection failed, falling back to SQLite: %v", err)
-			// Create data directory if it doesn't exist
-			if err := os.MkdirAll("data", 0755); err != nil {
-				return fmt.Errorf("failed to create data directory: %w", err)
-			}
-			DB, err = gorm.Open(sqlite.Dialector{
-			DriverName: "sqlite",
-			DSN:        "data/healthsecure.db",
-		}, gormConfig)
+	// Pick the backend from database.driver (mysql, sqlite, or postgres).
+	// Configs written before this setting existed fall back to the old
+	// hostname heuristic inside resolveDialect.
+	dialect, err := resolveDialect(config)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database dialect: %w", err)
+	}
+	activeDialect = dialect
+
+	DB, err = dialect.Open(config, gormConfig)
+	if err != nil {
+		if dialect.Name() != "sqlite" {
+			log.Printf("%s connection failed, falling back to SQLite: %v", dialect.Name(), err)
+			activeDialect = &sqliteDialect{}
+			DB, err = activeDialect.Open(config, gormConfig)
 			if err != nil {
 				return fmt.Errorf("failed to connect to SQLite database: %w", err)
 			}
 			log.Println("Using SQLite database for development")
-		}
-	} else {
-		// Default to SQLite for development
-		if err := os.MkdirAll("data", 0755); err != nil {
-			return fmt.Errorf("failed to create data directory: %w", err)
-		}
-		DB, err = gorm.Open(sqlite.Dialector{
-			DriverName: "sqlite",
-			DSN:        "data/healthsecure.db",
-		}, gormConfig)
-		if err != nil {
+		} else {
 			return fmt.Errorf("failed to connect to SQLite database: %w", err)
 		}
-		log.Println("Using SQLite database for development")
+	} else {
+		log.Printf("Using %s database", dialect.Name())
 	}
 
 	// Get underlying sql.DB for connection pool configuration
@@ -106,8 +83,17 @@ func Initialize(config *configs.Config) error {
 
 	log.Println("Database connection established successfully")
 
-	// Run auto migrations (can be disabled with SKIP_MIGRATIONS=true)
-	if skipMigrations := os.Getenv("SKIP_MIGRATIONS"); skipMigrations != "true" {
+	// In production, the schema is owned by the versioned migrations under
+	// migrations/ (see Migrator) and applied out-of-band with `healthsecure
+	// migrate up`. Startup refuses to boot unless the schema is clean and
+	// current; if migrations are pending, Migrator.RequireClean applies them
+	// before refusing. AutoMigrate is reserved for local dev/seed data,
+	// where schema drift is expected and convenience outweighs rigor.
+	if config.IsProduction() {
+		if err := requireMigratedSchema(); err != nil {
+			return fmt.Errorf("schema verification failed: %w", err)
+		}
+	} else if skipMigrations := os.Getenv("SKIP_MIGRATIONS"); skipMigrations != "true" {
 		if err := runMigrations(); err != nil {
 			log.Printf("Migration failed: %v", err)
 			log.Println("You can skip migrations by setting SKIP_MIGRATIONS=true environment variable")
@@ -127,6 +113,30 @@ func Initialize(config *configs.Config) error {
 	return nil
 }
 
+// requireMigratedSchema refuses to let the service boot against a schema
+// that is dirty or behind the versioned migrations in migrations/. Operators
+// apply those migrations explicitly via `healthsecure migrate up` as part of
+// deployment, so a mismatch here means a deploy step was skipped.
+func requireMigratedSchema() error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+
+	migrator, err := NewMigrator(sqlDB, DB.Dialector.Name())
+	if err != nil {
+		return fmt.Errorf("failed to initialize migrator: %w", err)
+	}
+	defer migrator.Close()
+
+	if err := migrator.RequireClean(); err != nil {
+		return err
+	}
+
+	log.Println("Schema verified up to date")
+	return nil
+}
+
 // runMigrations performs automatic schema migrations
 func runMigrations() error {
 	log.Println("Running database migrations...")
@@ -180,42 +190,28 @@ func runMigrations() error {
 	return nil
 }
 
-// handleExistingEnumColumns modifies existing enum columns to varchar
+// handleExistingEnumColumns brings any pre-existing enum-like columns in line
+// with what AutoMigrate expects, delegating the dialect-specific mechanics
+// (VARCHAR conversion, native ENUM/CHECK, or a table recreate) to the active
+// Dialect.
 func handleExistingEnumColumns() error {
-	dialectName := DB.Dialector.Name()
-
-	if dialectName == "mysql" {
-		// Check if users table exists and has enum role column
-		if DB.Migrator().HasTable("users") && DB.Migrator().HasColumn(&models.User{}, "role") {
-			// Try to alter the column if it's currently an enum
-			if err := DB.Exec("ALTER TABLE users MODIFY COLUMN role VARCHAR(20)").Error; err != nil {
-				log.Printf("Could not alter users.role column: %v", err)
-			}
-		}
-
-		// Check medical_records table
-		if DB.Migrator().HasTable("medical_records") && DB.Migrator().HasColumn(&models.MedicalRecord{}, "severity") {
-			if err := DB.Exec("ALTER TABLE medical_records MODIFY COLUMN severity VARCHAR(20)").Error; err != nil {
-				log.Printf("Could not alter medical_records.severity column: %v", err)
-			}
-		}
-	} else if dialectName == "sqlite" {
-		// SQLite doesn't support ALTER COLUMN, so we need to recreate tables if they have enum issues
-		// For now, just log and continue
-		log.Println("SQLite detected - enum columns will be handled during AutoMigrate")
+	if err := activeDialect.NormalizeEnumColumn(DB, &models.User{}, "users", "role"); err != nil {
+		log.Printf("Could not normalize users.role column: %v", err)
+	}
+	if err := activeDialect.NormalizeEnumColumn(DB, &models.MedicalRecord{}, "medical_records", "severity"); err != nil {
+		log.Printf("Could not normalize medical_records.severity column: %v", err)
 	}
-
 	return nil
 }
 
 // isIgnorableError checks if a migration error can be safely ignored
 func isIgnorableError(errStr, dialectName string) bool {
 	ignorablePatterns := []string{
-		"Error 1062:", // MySQL duplicate key
+		"Error 1062:",              // MySQL duplicate key
 		"UNIQUE constraint failed", // SQLite unique constraint
-		"Duplicate key name", // Generic duplicate key
-		"already exists", // Table/column exists
-		"duplicate column name", // Column already exists
+		"Duplicate key name",       // Generic duplicate key
+		"already exists",           // Table/column exists
+		"duplicate column name",    // Column already exists
 	}
 
 	for _, pattern := range ignorablePatterns {
@@ -227,41 +223,10 @@ func isIgnorableError(errStr, dialectName string) bool {
 	return false
 }
 
-// handleEnumMigration handles enum-related migration issues
+// handleEnumMigration handles enum-related migration issues by falling back
+// to hand-written DDL for the active dialect.
 func handleEnumMigration(model interface{}) error {
-	// Try to create the table without constraints first
-	switch model.(type) {
-	case *models.User:
-		return DB.Exec(`CREATE TABLE IF NOT EXISTS users (
-			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
-			email VARCHAR(191) UNIQUE NOT NULL,
-			password VARCHAR(255) NOT NULL,
-			role VARCHAR(20) NOT NULL,
-			name VARCHAR(100) NOT NULL,
-			active BOOLEAN DEFAULT TRUE,
-			last_login DATETIME,
-			created_at DATETIME,
-			updated_at DATETIME,
-			INDEX idx_users_email (email)
-		)`).Error
-	case *models.MedicalRecord:
-		return DB.Exec(`CREATE TABLE IF NOT EXISTS medical_records (
-			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
-			patient_id BIGINT UNSIGNED NOT NULL,
-			doctor_id BIGINT UNSIGNED NOT NULL,
-			diagnosis TEXT,
-			treatment TEXT,
-			notes TEXT,
-			medications TEXT,
-			severity VARCHAR(20),
-			created_at DATETIME,
-			updated_at DATETIME,
-			INDEX idx_medical_records_patient_id (patient_id),
-			INDEX idx_medical_records_doctor_id (doctor_id)
-		)`).Error
-	}
-
-	return nil
+	return activeDialect.CreateTableFallback(DB, model)
 }
 
 // Additional models for system functionality
@@ -344,7 +309,16 @@ type SecurityEvent struct {
 	ResolvedAt  *time.Time            `json:"resolved_at"`
 	CreatedAt   time.Time             `json:"created_at" gorm:"autoCreateTime;index"`
 
-	User         *models.User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	// PrevHash/RowHash chain security_events into a tamper-evident log: each
+	// row hashes its predecessor's RowHash together with its own contents, so
+	// altering or deleting a row breaks every hash after it. See
+	// VerifyAuditChain. Hashes are HMAC-SHA256 under securityEventChainKey;
+	// audit_event_chain (internal/audit) is chained separately under its own
+	// HMAC key.
+	PrevHash string `json:"prev_hash" gorm:"column:prev_hash;size:64"`
+	RowHash  string `json:"row_hash" gorm:"column:row_hash;size:64;index"`
+
+	User           *models.User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	ResolvedByUser *models.User `json:"resolved_by_user,omitempty" gorm:"foreignKey:ResolvedBy"`
 }
 
@@ -352,6 +326,44 @@ func (se *SecurityEvent) TableName() string {
 	return "security_events"
 }
 
+// DecryptedDetails returns se.Details with AES-GCM field encryption reversed
+// (a no-op for rows written while encryption was disabled).
+func (se *SecurityEvent) DecryptedDetails() (string, error) {
+	return DecryptField(se.Details)
+}
+
+// BeforeCreate encrypts Details at rest and extends the tamper-evident hash
+// chain: it looks up the current chain head's RowHash, stores it as
+// PrevHash, and computes RowHash from PrevHash plus this row's own
+// (already-encrypted) contents.
+func (se *SecurityEvent) BeforeCreate(tx *gorm.DB) error {
+	if se.CreatedAt.IsZero() {
+		se.CreatedAt = time.Now()
+	}
+
+	encrypted, err := EncryptField(se.Details)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt security event details: %w", err)
+	}
+	se.Details = encrypted
+
+	var head SecurityEvent
+	if err := tx.Order("id DESC").First(&head).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to read security event chain head: %w", err)
+		}
+	} else {
+		se.PrevHash = head.RowHash
+	}
+
+	hash, err := computeSecurityEventHash(se.PrevHash, se)
+	if err != nil {
+		return err
+	}
+	se.RowHash = hash
+	return nil
+}
+
 // GetDB returns the database instance
 func GetDB() *gorm.DB {
 	return DB
@@ -433,6 +445,10 @@ func RunCleanupTasks() error {
 		UpdateEmergencyAccessStatus,
 	}
 
+	if os.Getenv("AUDIT_ANCHORING_ENABLED") == "true" {
+		tasks = append(tasks, AnchorAuditChainHead)
+	}
+
 	for _, task := range tasks {
 		if err := task(); err != nil {
 			log.Printf("Cleanup task failed: %v", err)
@@ -465,9 +481,16 @@ func SetSystemSetting(key, value, description string, updatedBy uint) error {
 	return DB.Save(&setting).Error
 }
 
-// StartCleanupScheduler runs cleanup tasks periodically
-func StartCleanupScheduler() {
+// StartCleanupScheduler runs cleanup tasks periodically until ctx is
+// cancelled, at which point the goroutine exits and closes done, so callers
+// (e.g. Server's shutdown sequence) can wait for it to actually stop instead
+// of leaking it.
+func StartCleanupScheduler(ctx context.Context) (done <-chan struct{}) {
+	stopped := make(chan struct{})
+
 	go func() {
+		defer close(stopped)
+
 		ticker := time.NewTicker(1 * time.Hour) // Run every hour
 		defer ticker.Stop()
 
@@ -475,11 +498,15 @@ func StartCleanupScheduler() {
 			select {
 			case <-ticker.C:
 				RunCleanupTasks()
+			case <-ctx.Done():
+				log.Println("Database cleanup scheduler stopping")
+				return
 			}
 		}
 	}()
 
 	log.Println("Database cleanup scheduler started")
+	return stopped
 }
 
 // seedDemoUsers creates demo users for development if they don't exist
@@ -532,4 +559,4 @@ func seedDemoUsers() error {
 
 	log.Println("Demo users seeded successfully")
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"fmt"
+
+	"healthsecure/configs"
+
+	"gorm.io/gorm"
+)
+
+// Dialect hides the differences between the SQL backends HealthSecure can run
+// against behind a single interface, so Initialize no longer has to branch on
+// hostname heuristics or dialect-specific DDL. Each backend registers itself
+// via RegisterDialect from an init func in its own file.
+type Dialect interface {
+	// Name is the value operators set in database.driver (e.g. "mysql").
+	Name() string
+
+	// Open establishes a GORM connection for this dialect using the given
+	// config and GORM options.
+	Open(config *configs.Config, gormConfig *gorm.Config) (*gorm.DB, error)
+
+	// DSN returns the connection string for this dialect, for logging.
+	DSN(config *configs.Config) string
+
+	// NormalizeEnumColumn brings an existing enum-like column on model in line
+	// with the dialect's preferred representation (table/column name the
+	// column's SQL name).
+	NormalizeEnumColumn(db *gorm.DB, model interface{}, table, column string) error
+
+	// CreateTableFallback creates the table for model using hand-written DDL,
+	// used when AutoMigrate fails on a dialect-specific enum or syntax error.
+	CreateTableFallback(db *gorm.DB, model interface{}) error
+
+	// SupportsJSON reports whether the dialect has a native JSON column type.
+	SupportsJSON() bool
+}
+
+var dialects = map[string]Dialect{}
+
+// RegisterDialect makes d available under d.Name() for resolveDialect.
+func RegisterDialect(d Dialect) {
+	dialects[d.Name()] = d
+}
+
+// resolveDialect picks the Dialect named by config.Database.Driver. For
+// configs written before the driver setting existed, it falls back to the
+// hostname heuristic the old Initialize used: a real host implies MySQL,
+// anything else implies SQLite.
+func resolveDialect(config *configs.Config) (Dialect, error) {
+	driver := config.Database.Driver
+	if driver == "" {
+		if config.Database.Host != "" && config.Database.Host != "localhost" && config.Database.Host != "127.0.0.1" {
+			driver = "mysql"
+		} else {
+			driver = "sqlite"
+		}
+	}
+
+	d, ok := dialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("unknown database driver %q (want mysql, sqlite, or postgres)", driver)
+	}
+	return d, nil
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"strings"
+
+	"healthsecure/configs"
+	"healthsecure/internal/models"
+
+	"github.com/go-sql-driver/mysql"
+	gorm_mysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterDialect(&mysqlDialect{})
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) Open(config *configs.Config, gormConfig *gorm.Config) (*gorm.DB, error) {
+	// Register custom TLS config for Railway MySQL
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true, // For Railway, skip certificate verification
+	}
+	if err := mysql.RegisterTLSConfig("railway", tlsConfig); err != nil {
+		log.Printf("Failed to register TLS config: %v", err)
+	}
+
+	return gorm.Open(gorm_mysql.Open(mysqlDialect{}.DSN(config)), gormConfig)
+}
+
+func (mysqlDialect) DSN(config *configs.Config) string {
+	dsn := config.GetDatabaseDSN()
+	if config.Database.TLSMode != "" && config.Database.TLSMode != "preferred" {
+		// Replace the tls parameter with our custom config
+		dsn = strings.Replace(dsn, "&tls="+config.Database.TLSMode, "&tls=railway", 1)
+	} else {
+		// Add TLS if not present
+		dsn += "&tls=railway"
+	}
+	return dsn
+}
+
+// NormalizeEnumColumn converts an enum-typed column to VARCHAR, since GORM's
+// AutoMigrate expects to own the column type and fights with hand-declared
+// MySQL ENUMs.
+func (mysqlDialect) NormalizeEnumColumn(db *gorm.DB, model interface{}, table, column string) error {
+	if !db.Migrator().HasTable(table) || !db.Migrator().HasColumn(model, column) {
+		return nil
+	}
+	sql := fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s VARCHAR(20)", table, column)
+	if err := db.Exec(sql).Error; err != nil {
+		log.Printf("Could not alter %s.%s column: %v", table, column, err)
+	}
+	return nil
+}
+
+func (mysqlDialect) CreateTableFallback(db *gorm.DB, model interface{}) error {
+	switch model.(type) {
+	case *models.User:
+		return db.Exec(`CREATE TABLE IF NOT EXISTS users (
+			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+			email VARCHAR(191) UNIQUE NOT NULL,
+			password VARCHAR(255) NOT NULL,
+			role VARCHAR(20) NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			active BOOLEAN DEFAULT TRUE,
+			last_login DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME,
+			INDEX idx_users_email (email)
+		)`).Error
+	case *models.MedicalRecord:
+		return db.Exec(`CREATE TABLE IF NOT EXISTS medical_records (
+			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+			patient_id BIGINT UNSIGNED NOT NULL,
+			doctor_id BIGINT UNSIGNED NOT NULL,
+			diagnosis TEXT,
+			treatment TEXT,
+			notes TEXT,
+			medications TEXT,
+			severity VARCHAR(20),
+			created_at DATETIME,
+			updated_at DATETIME,
+			INDEX idx_medical_records_patient_id (patient_id),
+			INDEX idx_medical_records_doctor_id (doctor_id)
+		)`).Error
+	}
+	return nil
+}
+
+func (mysqlDialect) SupportsJSON() bool { return true }
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"healthsecure/configs"
+	"healthsecure/internal/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterDialect(&postgresDialect{})
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (d postgresDialect) Open(config *configs.Config, gormConfig *gorm.Config) (*gorm.DB, error) {
+	return gorm.Open(postgres.Open(d.DSN(config)), gormConfig)
+}
+
+func (postgresDialect) DSN(config *configs.Config) string {
+	return config.GetDatabaseDSN()
+}
+
+// NormalizeEnumColumn converts an existing column to VARCHAR(20), since
+// Postgres enforces column types strictly enough that AutoMigrate would
+// otherwise reject a pre-existing column of the wrong type rather than widen
+// it like MySQL does. It adds no CHECK constraint; only the users table
+// created by CreateTableFallback declares one.
+func (postgresDialect) NormalizeEnumColumn(db *gorm.DB, model interface{}, table, column string) error {
+	if !db.Migrator().HasTable(table) || !db.Migrator().HasColumn(model, column) {
+		return nil
+	}
+	sql := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE VARCHAR(20)", table, column)
+	if err := db.Exec(sql).Error; err != nil {
+		log.Printf("Could not alter %s.%s column: %v", table, column, err)
+	}
+	return nil
+}
+
+func (postgresDialect) CreateTableFallback(db *gorm.DB, model interface{}) error {
+	switch model.(type) {
+	case *models.User:
+		return db.Exec(`CREATE TABLE IF NOT EXISTS users (
+			id BIGSERIAL PRIMARY KEY,
+			email VARCHAR(191) UNIQUE NOT NULL,
+			password VARCHAR(255) NOT NULL,
+			role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'doctor', 'nurse', 'device')),
+			name VARCHAR(100) NOT NULL,
+			active BOOLEAN DEFAULT TRUE,
+			last_login TIMESTAMP,
+			created_at TIMESTAMP,
+			updated_at TIMESTAMP
+		)`).Error
+	case *models.MedicalRecord:
+		return db.Exec(`CREATE TABLE IF NOT EXISTS medical_records (
+			id BIGSERIAL PRIMARY KEY,
+			patient_id BIGINT NOT NULL,
+			doctor_id BIGINT NOT NULL,
+			diagnosis TEXT,
+			treatment TEXT,
+			notes TEXT,
+			medications TEXT,
+			severity VARCHAR(20),
+			created_at TIMESTAMP,
+			updated_at TIMESTAMP
+		)`).Error
+	}
+	return nil
+}
+
+func (postgresDialect) SupportsJSON() bool { return true }
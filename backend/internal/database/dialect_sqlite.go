@@ -0,0 +1,100 @@
+package database
+
+import (
+	"fmt"
+	"os"
+
+	"healthsecure/configs"
+	"healthsecure/internal/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	_ "modernc.org/sqlite" // Pure Go SQLite driver
+)
+
+func init() {
+	RegisterDialect(&sqliteDialect{})
+}
+
+const sqliteDSN = "data/healthsecure.db"
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) Open(config *configs.Config, gormConfig *gorm.Config) (*gorm.DB, error) {
+	if err := os.MkdirAll("data", 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	return gorm.Open(sqlite.Dialector{
+		DriverName: "sqlite",
+		DSN:        sqliteDSN,
+	}, gormConfig)
+}
+
+func (sqliteDialect) DSN(config *configs.Config) string {
+	return sqliteDSN
+}
+
+// NormalizeEnumColumn recreates the table under a temporary name, copies the
+// data across, and swaps it back in, since SQLite has no ALTER COLUMN TYPE.
+func (sqliteDialect) NormalizeEnumColumn(db *gorm.DB, model interface{}, table, column string) error {
+	if !db.Migrator().HasTable(table) || !db.Migrator().HasColumn(model, column) {
+		return nil
+	}
+
+	tmpTable := table + "_enum_migration_tmp"
+	return db.Transaction(func(tx *gorm.DB) error {
+		if tx.Migrator().HasTable(tmpTable) {
+			if err := tx.Migrator().DropTable(tmpTable); err != nil {
+				return fmt.Errorf("failed to drop stale %s: %w", tmpTable, err)
+			}
+		}
+		if err := tx.Table(tmpTable).AutoMigrate(model); err != nil {
+			return fmt.Errorf("failed to create %s: %w", tmpTable, err)
+		}
+		if err := tx.Exec(fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", tmpTable, table)).Error; err != nil {
+			return fmt.Errorf("failed to copy rows into %s: %w", tmpTable, err)
+		}
+		if err := tx.Migrator().DropTable(table); err != nil {
+			return fmt.Errorf("failed to drop %s: %w", table, err)
+		}
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tmpTable, table)).Error; err != nil {
+			return fmt.Errorf("failed to rename %s to %s: %w", tmpTable, table, err)
+		}
+		return nil
+	})
+}
+
+func (sqliteDialect) CreateTableFallback(db *gorm.DB, model interface{}) error {
+	switch model.(type) {
+	case *models.User:
+		return db.Exec(`CREATE TABLE IF NOT EXISTS users (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			email VARCHAR(191) UNIQUE NOT NULL,
+			password VARCHAR(255) NOT NULL,
+			role VARCHAR(20) NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			active BOOLEAN DEFAULT TRUE,
+			last_login DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME
+		)`).Error
+	case *models.MedicalRecord:
+		return db.Exec(`CREATE TABLE IF NOT EXISTS medical_records (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			patient_id INTEGER NOT NULL,
+			doctor_id INTEGER NOT NULL,
+			diagnosis TEXT,
+			treatment TEXT,
+			notes TEXT,
+			medications TEXT,
+			severity VARCHAR(20),
+			created_at DATETIME,
+			updated_at DATETIME
+		)`).Error
+	}
+	return nil
+}
+
+func (sqliteDialect) SupportsJSON() bool { return false }
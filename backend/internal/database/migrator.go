@@ -0,0 +1,132 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+)
+
+// MigrationsPath is where the versioned migrations/NNNN_name.up.sql /
+// .down.sql pairs live, relative to the process's working directory.
+const MigrationsPath = "migrations"
+
+// Migrator drives golang-migrate against whichever dialect Initialize
+// connected to, so schema changes are numbered, reversible, and applied the
+// same way in every environment instead of relying on AutoMigrate's
+// best-effort column diffing.
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+// NewMigrator builds a Migrator bound to sqlDB's dialect ("mysql" or
+// "sqlite"). AutoMigrate-based setup is still used for local dev/seed
+// databases; production startup goes through this instead.
+func NewMigrator(sqlDB *sql.DB, dialect string) (*Migrator, error) {
+	var (
+		driver database.Driver
+		err    error
+	)
+
+	switch dialect {
+	case "mysql":
+		driver, err = mysql.WithInstance(sqlDB, &mysql.Config{})
+	case "sqlite":
+		driver, err = sqlite3.WithInstance(sqlDB, &sqlite3.Config{})
+	default:
+		return nil, fmt.Errorf("unsupported dialect for migrations: %s", dialect)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to build migration driver for %s: %w", dialect, err)
+	}
+
+	m, err := migrate.NewWithDatabaseInstance("file://"+MigrationsPath, dialect, driver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize migrator: %w", err)
+	}
+
+	return &Migrator{m: m}, nil
+}
+
+// Up applies all pending migrations.
+func (mg *Migrator) Up() error {
+	if err := mg.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Down rolls back every applied migration.
+func (mg *Migrator) Down() error {
+	if err := mg.m.Down(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Goto migrates up or down to the exact version given.
+func (mg *Migrator) Goto(version uint) error {
+	if err := mg.m.Migrate(version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Force sets the migration version without running any migration, for
+// clearing a dirty state after a manual fix.
+func (mg *Migrator) Force(version int) error {
+	return mg.m.Force(version)
+}
+
+// Version returns the current schema version and whether it's dirty (a
+// previous migration failed partway through).
+func (mg *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// RequireClean fails startup if the schema is dirty (a previous migration
+// failed partway through) or behind the migrations on disk, so production
+// never serves traffic against an indeterminate schema. When the schema is
+// behind, RequireClean applies the pending migrations itself and still
+// returns an error, so the process has to be restarted to boot against the
+// migrated schema.
+func (mg *Migrator) RequireClean() error {
+	version, dirty, err := mg.Version()
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("schema is dirty at version %d - run `healthsecure migrate force <version>` after fixing it manually", version)
+	}
+
+	switch err := mg.m.Up(); {
+	case errors.Is(err, migrate.ErrNoChange):
+		// Already at the latest version.
+	case err == nil:
+		return fmt.Errorf("schema was behind and has now been migrated to the latest version - restart to boot against it")
+	default:
+		return fmt.Errorf("schema is behind and failed to migrate: %w", err)
+	}
+
+	return nil
+}
+
+// Close releases the underlying source and database connections the
+// migrator opened. The caller-owned *sql.DB passed to NewMigrator is left
+// open.
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+	if srcErr != nil {
+		return srcErr
+	}
+	return dbErr
+}
@@ -0,0 +1,121 @@
+// Package enrollment implements a small RFC 8555 (ACME) subset — newNonce,
+// newAccount, newOrder, finalize, certificate — so clinical devices (bedside
+// tablets, lab instruments) can self-enroll for short-lived mTLS client
+// certificates instead of authenticating as a human user.
+package enrollment
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// IntermediateCA is an in-process signer for device client certificates. In
+// production this would be backed by an HSM-held key or a real PKI service;
+// the in-memory key here matches the "embedded ... provisioner" scope of
+// this request.
+type IntermediateCA struct {
+	cert       *x509.Certificate
+	privateKey *ecdsa.PrivateKey
+}
+
+func NewIntermediateCA(commonName string) (*IntermediateCA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA serial: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName, Organization: []string{"HealthSecure"}},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().AddDate(5, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to self-sign CA certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+
+	return &IntermediateCA{cert: cert, privateKey: key}, nil
+}
+
+func (ca *IntermediateCA) Certificate() *x509.Certificate {
+	return ca.cert
+}
+
+// SignCSR issues a short-lived leaf certificate for the given CSR, restricted
+// to identifiers - the order's policy-approved SANs, not whatever the CSR
+// itself declares - and capped at lifetime (the provisioner enforces the
+// policy's MaxCertLifetime before calling this). The CSR is only trusted for
+// its public key and Subject once its signature checks out; its own
+// DNSNames/URIs are never copied into the signed certificate, since a device
+// could otherwise self-declare SANs its order was never approved for.
+func (ca *IntermediateCA) SignCSR(csr *x509.CertificateRequest, identifiers []string, lifetime time.Duration) (*x509.Certificate, error) {
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid CSR signature: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate certificate serial: %w", err)
+	}
+
+	dnsNames, uris := splitIdentifiers(identifiers)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      csr.Subject,
+		DNSNames:     dnsNames,
+		URIs:         uris,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(lifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, csr.PublicKey, ca.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign device certificate: %w", err)
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+// splitIdentifiers sorts an order's approved identifiers (a mix of plain DNS
+// names and URI-style identifiers such as SPIFFE IDs - see order.Identifiers)
+// into the two SAN slices x509.Certificate needs. An identifier that fails to
+// parse as a URI is treated as a DNS name, since that's what it would have
+// been validated as by Policy.AllowedSANsByWard in the first place.
+func splitIdentifiers(identifiers []string) (dnsNames []string, uris []*url.URL) {
+	for _, id := range identifiers {
+		if strings.Contains(id, "://") {
+			if u, err := url.Parse(id); err == nil {
+				uris = append(uris, u)
+				continue
+			}
+		}
+		dnsNames = append(dnsNames, id)
+	}
+	return dnsNames, uris
+}
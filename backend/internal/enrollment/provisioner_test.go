@@ -0,0 +1,116 @@
+package enrollment
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateCSR(t *testing.T, commonName string, dnsNames []string) ([]byte, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: commonName},
+		DNSNames: dnsNames,
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	require.NoError(t, err)
+
+	return der, key
+}
+
+func newTestProvisioner(t *testing.T) *Provisioner {
+	ca, err := NewIntermediateCA("HealthSecure Test Intermediate CA")
+	require.NoError(t, err)
+
+	policy := DefaultPolicy()
+	policy.AllowedSANsByWard["icu-3"] = []string{"tablet-icu3-07.devices.healthsecure.local"}
+
+	return NewProvisioner(ca, policy)
+}
+
+func TestProvisioner_FullEnrollmentFlow(t *testing.T) {
+	p := newTestProvisioner(t)
+
+	nonce := p.NewNonce()
+	acc, err := p.NewAccount(nonce, "SN-ICU3-07", "icu-3")
+	require.NoError(t, err)
+
+	identifier := "tablet-icu3-07.devices.healthsecure.local"
+
+	nonce = p.NewNonce()
+	ord, err := p.NewOrder(nonce, acc.ID, []string{identifier}, ChallengeHTTP01)
+	require.NoError(t, err)
+	assert.Equal(t, orderStatusPending, ord.Status)
+
+	require.NoError(t, p.ValidateChallenge(ord.ID, ord.Challenge.Token))
+
+	csrDER, _ := generateCSR(t, identifier, []string{identifier})
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	finalized, err := p.Finalize(ord.ID, csrPEM)
+	require.NoError(t, err)
+	assert.Equal(t, orderStatusValid, finalized.Status)
+
+	certPEM, err := p.Certificate(ord.ID)
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(certPEM)
+	require.NotNil(t, block)
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	assert.Contains(t, cert.DNSNames, identifier)
+	assert.Equal(t, x509.ExtKeyUsageClientAuth, cert.ExtKeyUsage[0])
+}
+
+func TestProvisioner_RejectsUnlistedSAN(t *testing.T) {
+	p := newTestProvisioner(t)
+
+	nonce := p.NewNonce()
+	acc, err := p.NewAccount(nonce, "SN-ICU3-08", "icu-3")
+	require.NoError(t, err)
+
+	nonce = p.NewNonce()
+	_, err = p.NewOrder(nonce, acc.ID, []string{"not-allowed.devices.healthsecure.local"}, ChallengeHTTP01)
+	assert.Error(t, err)
+}
+
+func TestProvisioner_FinalizeBeforeChallengeValidationFails(t *testing.T) {
+	p := newTestProvisioner(t)
+
+	nonce := p.NewNonce()
+	acc, err := p.NewAccount(nonce, "SN-ICU3-09", "icu-3")
+	require.NoError(t, err)
+
+	identifier := "tablet-icu3-07.devices.healthsecure.local"
+	nonce = p.NewNonce()
+	ord, err := p.NewOrder(nonce, acc.ID, []string{identifier}, ChallengeHTTP01)
+	require.NoError(t, err)
+
+	csrDER, _ := generateCSR(t, identifier, []string{identifier})
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	_, err = p.Finalize(ord.ID, csrPEM)
+	assert.Error(t, err)
+}
+
+func TestProvisioner_NonceIsSingleUse(t *testing.T) {
+	p := newTestProvisioner(t)
+
+	nonce := p.NewNonce()
+	_, err := p.NewAccount(nonce, "SN-ICU3-10", "icu-3")
+	require.NoError(t, err)
+
+	_, err = p.NewAccount(nonce, "SN-ICU3-10", "icu-3")
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+// Package errs defines a small typed error taxonomy shared by services and
+// handlers. A service returns one of these instead of a raw fmt.Errorf so
+// callers can branch on Code (errors.Is(err, errs.ErrNotFound)) instead of
+// matching on error message substrings, and a single Gin middleware
+// (Handler) can translate any of them into the right HTTP status.
+package errs
+
+import "net/http"
+
+// Code classifies what went wrong, independent of the underlying cause. It
+// implements error itself so the constants below double as errors.Is
+// targets: errors.Is(err, errs.ErrNotFound) works without a separate
+// sentinel value per code.
+type Code string
+
+func (c Code) Error() string { return string(c) }
+
+const (
+	ErrValidationFailed Code = "VALIDATION_FAILED"
+	ErrNotFound         Code = "NOT_FOUND"
+	ErrNoPermission     Code = "NO_PERMISSION"
+	ErrConflict         Code = "CONFLICT"
+	ErrAlreadyExists    Code = "ALREADY_EXISTS"
+	ErrExternal         Code = "EXTERNAL"
+	ErrDeadlineExceeded Code = "DEADLINE_EXCEEDED"
+	ErrUnauthenticated  Code = "UNAUTHENTICATED"
+	ErrInternal         Code = "INTERNAL"
+	ErrUnimplemented    Code = "UNIMPLEMENTED"
+	ErrBadInput         Code = "BAD_INPUT"
+)
+
+// httpStatus maps each Code to the status Handler responds with.
+var httpStatus = map[Code]int{
+	ErrValidationFailed: http.StatusBadRequest,
+	ErrBadInput:         http.StatusBadRequest,
+	ErrNotFound:         http.StatusNotFound,
+	ErrNoPermission:     http.StatusForbidden,
+	ErrConflict:         http.StatusConflict,
+	ErrAlreadyExists:    http.StatusConflict,
+	ErrExternal:         http.StatusBadGateway,
+	ErrDeadlineExceeded: http.StatusGatewayTimeout,
+	ErrUnauthenticated:  http.StatusUnauthorized,
+	ErrUnimplemented:    http.StatusNotImplemented,
+	ErrInternal:         http.StatusInternalServerError,
+}
+
+// Error is a typed, wrappable error: Code drives HTTP-status translation,
+// Message is the stable, user-safe text returned to clients, and Fields
+// carries optional per-field validation details (e.g. {"email": "required"}).
+// cause is the underlying error, if any - Unwrap exposes it so
+// errors.Is/errors.As still see through an Error to what caused it.
+type Error struct {
+	Code    Code
+	Message string
+	Fields  map[string]string
+	cause   error
+}
+
+// New creates an Error with no wrapped cause.
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap creates an Error that carries cause for logging/Unwrap, while keeping
+// message as the stable, user-facing text (cause may leak internal details
+// callers shouldn't see).
+func Wrap(code Code, cause error, message string) *Error {
+	return &Error{Code: code, Message: message, cause: cause}
+}
+
+// WithFields attaches validation details and returns e for chaining, e.g.
+// errs.New(errs.ErrValidationFailed, "invalid request").WithFields(fields).
+func (e *Error) WithFields(fields map[string]string) *Error {
+	e.Fields = fields
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.cause }
+
+// Is lets errors.Is(err, errs.ErrNotFound) match on Code alone, ignoring
+// Message/Fields/cause.
+func (e *Error) Is(target error) bool {
+	code, ok := target.(Code)
+	if !ok {
+		return false
+	}
+	return e.Code == code
+}
+
+// HTTPStatus returns the HTTP status Handler responds with for e's Code.
+func (e *Error) HTTPStatus() int {
+	if status, ok := httpStatus[e.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
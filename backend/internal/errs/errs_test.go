@@ -0,0 +1,55 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_IsMatchesByCodeThroughWrapping(t *testing.T) {
+	cause := errors.New("record not found")
+	err := Wrap(ErrNotFound, cause, "thread not found")
+
+	wrapped := fmt.Errorf("get thread: %w", err)
+
+	assert.True(t, errors.Is(wrapped, ErrNotFound))
+	assert.False(t, errors.Is(wrapped, ErrConflict))
+}
+
+func TestError_UnwrapExposesCause(t *testing.T) {
+	cause := errors.New("boom")
+	err := Wrap(ErrExternal, cause, "AI service unavailable")
+
+	assert.Equal(t, cause, errors.Unwrap(err))
+}
+
+func TestError_HTTPStatus(t *testing.T) {
+	cases := []struct {
+		code     Code
+		expected int
+	}{
+		{ErrValidationFailed, 400},
+		{ErrNotFound, 404},
+		{ErrNoPermission, 403},
+		{ErrConflict, 409},
+		{ErrExternal, 502},
+		{ErrUnauthenticated, 401},
+		{ErrInternal, 500},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.code), func(t *testing.T) {
+			assert.Equal(t, tc.expected, New(tc.code, "msg").HTTPStatus())
+		})
+	}
+}
+
+func TestError_WithFieldsAttachesValidationDetails(t *testing.T) {
+	err := New(ErrValidationFailed, "invalid request").WithFields(map[string]string{
+		"message": "required",
+	})
+
+	assert.Equal(t, "required", err.Fields["message"])
+}
@@ -0,0 +1,45 @@
+package errs
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// body is the {code, message, fields} shape every translated error renders
+// as, so the frontend can branch on code instead of parsing message text.
+type body struct {
+	Code    Code              `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// Handler is a Gin middleware that replaces the dozens of ad-hoc
+// c.JSON(status, gin.H{"error": ...}) call sites: a handler calls
+// c.Error(err) with any error (typed or not) and returns, and Handler
+// renders the response after the chain finishes. A *errs.Error maps to its
+// own Code/HTTPStatus; any other error is treated as an unclassified
+// ErrInternal so a forgotten typed-error site still fails closed with a safe
+// message instead of leaking the raw error.
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+
+		var typed *Error
+		if !errors.As(err, &typed) {
+			typed = Wrap(ErrInternal, err, "internal server error")
+		}
+
+		c.JSON(typed.HTTPStatus(), body{
+			Code:    typed.Code,
+			Message: typed.Message,
+			Fields:  typed.Fields,
+		})
+	}
+}
@@ -1,24 +1,46 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 
+	"healthsecure/internal/errs"
 	"healthsecure/internal/models"
+	"healthsecure/internal/phi"
 	"healthsecure/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type AIHandler struct {
-	patientService *services.PatientService
+	patientService    *services.PatientService
+	chatThreadService *services.ChatThreadService
+	userService       *services.UserService
+	auditService      *services.AuditService
+	aiServiceClient   *AIServiceClient
+	redactor          phi.Redactor
 }
 
-func NewAIHandler(patientService *services.PatientService) *AIHandler {
+func NewAIHandler(patientService *services.PatientService, chatThreadService *services.ChatThreadService, userService *services.UserService, auditService *services.AuditService, aiServiceClient *AIServiceClient, redactor phi.Redactor) *AIHandler {
 	return &AIHandler{
-		patientService: patientService,
+		patientService:    patientService,
+		chatThreadService: chatThreadService,
+		userService:       userService,
+		auditService:      auditService,
+		aiServiceClient:   aiServiceClient,
+		redactor:          redactor,
 	}
 }
 
+// GetPatients projects patients to the AI-safe models.AIPatient view. Name is
+// sent as-is (the AI needs to address the patient by name), but Description
+// is free text and runs through the PHI redactor before it leaves the
+// system; X-PHI-Findings-Count reports how many spans were redacted across
+// the whole response, and any redaction is audited.
 func (h *AIHandler) GetPatients(c *gin.Context) {
 	patients, err := h.patientService.GetAllPatients()
 	if err != nil {
@@ -26,18 +48,192 @@ func (h *AIHandler) GetPatients(c *gin.Context) {
 		return
 	}
 
-	// Convert to AI-safe format
 	var aiPatients []models.AIPatient
+	totalFindings := 0
 	for _, p := range patients {
+		description := p.Description
+		if h.redactor != nil {
+			redacted, findings := h.redactor.Redact(c.Request.Context(), description)
+			description = redacted
+			totalFindings += len(findings)
+		}
+
 		aiPatients = append(aiPatients, models.AIPatient{
 			ID:          p.ID,
 			Name:        p.GetFullName(),
 			Age:         p.GetAge(),
-			Description: p.Description,
+			Description: description,
 			CreatedAt:   p.CreatedAt,
 			UpdatedAt:   p.UpdatedAt,
 		})
 	}
 
+	c.Header("X-PHI-Findings-Count", strconv.Itoa(totalFindings))
+	if totalFindings > 0 {
+		userID, _ := c.Get("user_id")
+		userIDUint, _ := strconv.ParseUint(fmt.Sprintf("%v", userID), 10, 32)
+		h.auditService.LogUserAction(
+			uint(userIDUint),
+			models.ActionRead,
+			"ai_patients",
+			c.ClientIP(),
+			c.Request.UserAgent(),
+			true,
+			fmt.Sprintf("Redacted %d PHI finding(s) from AI-safe patient descriptions", totalFindings),
+		)
+	}
+
 	c.JSON(http.StatusOK, aiPatients)
 }
+
+// StreamChatRequest is the body for StreamChat: an existing thread and the
+// next user turn.
+type StreamChatRequest struct {
+	ThreadID string `json:"thread_id" binding:"required"`
+	Message  string `json:"message" binding:"required"`
+}
+
+// RunEvent is one SSE frame emitted by StreamChat. It carries its own run
+// tracing vocabulary (run_start/token/tool_call/run_end/error) rather than
+// AIChunk's (token/action/done/error), since a RunEvent describes a traced
+// run rather than a raw AI service chunk; only the fields relevant to Type
+// are populated.
+type RunEvent struct {
+	Type        string            `json:"type"`
+	RunID       string            `json:"run_id"`
+	ParentRunID *string           `json:"parent_run_id,omitempty"`
+	Content     string            `json:"content,omitempty"`
+	ToolName    string            `json:"tool_name,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// StreamChat handles {thread_id, message} over SSE (text/event-stream),
+// tracing the turn as a ChatRun: run_start carries the thread's
+// ThreadConfig.Metadata, token streams assistant content, tool_call
+// surfaces any actions the AI service reports, and run_end closes the run
+// with its final content. The run is persisted up front via StartRun so it
+// exists even if the stream errors or the client disconnects, and is always
+// closed out via CompleteRun before the handler returns.
+func (h *AIHandler) StreamChat(c *gin.Context) {
+	var req StreamChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.New(errs.ErrValidationFailed, fmt.Sprintf("invalid request format: %v", err)))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(errs.New(errs.ErrUnauthenticated, "unauthorized"))
+		return
+	}
+	userIDStr := fmt.Sprintf("%v", userID)
+
+	if _, err := h.chatThreadService.GetThread(req.ThreadID, userIDStr); err != nil {
+		c.Error(errs.New(errs.ErrNoPermission, "invalid or inaccessible thread ID"))
+		return
+	}
+
+	if _, err := h.chatThreadService.SaveMessage(req.ThreadID, "user", req.Message, ""); err != nil {
+		log.Printf("Failed to save user message: %v", err)
+	}
+
+	runID := uuid.NewString()
+	if _, err := h.chatThreadService.StartRun(runID, req.ThreadID, nil, req.Message, []string{"chat"}); err != nil {
+		c.Error(errs.Wrap(errs.ErrInternal, err, "failed to start chat run"))
+		return
+	}
+
+	aiReq, err := resolveAIServiceRequest(h.userService, h.chatThreadService, req.Message, "", "", userIDStr, req.ThreadID)
+	if err != nil {
+		_ = h.chatThreadService.CompleteRun(runID, "", "error")
+		c.Error(errs.Wrap(errs.ErrInternal, err, "failed to prepare AI request"))
+		return
+	}
+
+	// ctx is tied to the client connection: if the browser navigates away,
+	// gin cancels the request context, ChatStream's HTTP call is aborted,
+	// and the goroutine reading the upstream response exits, which in turn
+	// closes chunks and ends the range loop below.
+	chunks, err := h.aiServiceClient.ChatStream(c.Request.Context(), aiReq)
+	if err != nil {
+		_ = h.chatThreadService.CompleteRun(runID, "", "error")
+		c.Error(errs.Wrap(errs.ErrExternal, err, "failed to start AI response stream"))
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	flusher, canFlush := c.Writer.(http.Flusher)
+
+	writeEvent := func(event RunEvent) {
+		event.RunID = runID
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Type, payload)
+		if canFlush {
+			flusher.Flush()
+		}
+	}
+
+	writeEvent(RunEvent{Type: "run_start", Metadata: h.chatThreadService.CreateThreadConfig(req.ThreadID, userIDStr).Metadata})
+
+	var fullResponse, status string
+	status = "completed"
+	phiFindings := 0
+
+	for chunk := range chunks {
+		switch chunk.Type {
+		case "token":
+			content := chunk.Content
+			if h.redactor != nil {
+				redacted, findings := h.redactor.Redact(c.Request.Context(), content)
+				content = redacted
+				phiFindings += len(findings)
+			}
+			fullResponse += content
+			writeEvent(RunEvent{Type: "token", Content: content})
+		case "action":
+			for _, action := range chunk.Actions {
+				writeEvent(RunEvent{Type: "tool_call", ToolName: action})
+			}
+		case "error":
+			status = "error"
+			writeEvent(RunEvent{Type: "error", Error: chunk.Error})
+		}
+
+		if chunk.Type == "error" {
+			break
+		}
+	}
+
+	if err := h.chatThreadService.CompleteRun(runID, fullResponse, status); err != nil {
+		log.Printf("Failed to complete chat run %s: %v", runID, err)
+	}
+
+	if status != "error" {
+		if _, err := h.chatThreadService.SaveMessage(req.ThreadID, "assistant", fullResponse, runID); err != nil {
+			log.Printf("Failed to save assistant message: %v", err)
+		}
+	}
+
+	if phiFindings > 0 {
+		userIDUint, _ := strconv.ParseUint(userIDStr, 10, 32)
+		h.auditService.LogUserAction(
+			uint(userIDUint),
+			models.ActionRead,
+			fmt.Sprintf("chat_run:%s", runID),
+			c.ClientIP(),
+			c.Request.UserAgent(),
+			true,
+			fmt.Sprintf("Redacted %d PHI finding(s) from streamed assistant response", phiFindings),
+		)
+	}
+
+	writeEvent(RunEvent{Type: "run_end", Content: fullResponse})
+}
@@ -1,32 +1,64 @@
 package handlers
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"healthsecure/configs"
+	"healthsecure/internal/database"
 	"healthsecure/internal/models"
+	"healthsecure/internal/resilience"
+
+	"gorm.io/gorm"
 )
 
-// AIServiceClient handles communication with the Python AI service
+// retryableError marks a Chat failure as originating from the HTTP
+// round trip itself (timeout, connection failure, 5xx) rather than from the
+// AI service rejecting the request - only these count toward retries and the
+// circuit breaker.
+type retryableError struct{ err error }
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+func isRetryableChatError(err error) bool {
+	var re *retryableError
+	return errors.As(err, &re)
+}
+
+// AIServiceClient handles communication with the Python AI service. It
+// guards Chat with a bulkhead (bounded concurrency), a circuit breaker, and
+// retries, so a slow or failing Python service degrades gracefully instead
+// of tying up Go server goroutines indefinitely.
 type AIServiceClient struct {
 	baseURL    string
 	httpClient *http.Client
+	db         *gorm.DB
+
+	breaker     *resilience.CircuitBreaker
+	bulkhead    *resilience.Bulkhead
+	retryConfig resilience.RetryConfig
 }
 
 // AIServiceRequest represents a request to the AI service
 type AIServiceRequest struct {
-	Message   string `json:"message"`
-	ThreadID  string `json:"thread_id"`
-	UserID    string `json:"user_id"`
-	UserRole  string `json:"user_role"`
-	UserName  string `json:"user_name"`
+	Message   string               `json:"message"`
+	ThreadID  string               `json:"thread_id"`
+	UserID    string               `json:"user_id"`
+	UserRole  string               `json:"user_role"`
+	UserName  string               `json:"user_name"`
 	History   []models.ChatMessage `json:"history,omitempty"`
-	MaxTokens *int   `json:"max_tokens,omitempty"`
+	MaxTokens *int                 `json:"max_tokens,omitempty"`
 }
 
 // AIServiceResponse represents a response from the AI service
@@ -42,30 +74,119 @@ type AIServiceResponse struct {
 	Details    string `json:"details,omitempty"`
 }
 
-// NewAIServiceClient creates a new AI service client
-func NewAIServiceClient() *AIServiceClient {
+// AIChunk is one event of a streamed AI response. Type is one of "token",
+// "action", "done", or "error"; only the fields relevant to that type are
+// populated.
+type AIChunk struct {
+	Type       string   `json:"type"`
+	Content    string   `json:"content,omitempty"`
+	Actions    []string `json:"actions,omitempty"`
+	RunID      string   `json:"run_id,omitempty"`
+	ModelUsed  string   `json:"model_used,omitempty"`
+	NewTitle   string   `json:"new_title,omitempty"`
+	TokensUsed *int     `json:"tokens_used,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// NewAIServiceClient creates a new AI service client. db is used only to
+// emit SecurityEvent rows when the circuit breaker trips or half-opens; it
+// may be nil (e.g. in tests), in which case those events are skipped.
+func NewAIServiceClient(db *gorm.DB, config *configs.Config) *AIServiceClient {
 	baseURL := os.Getenv("AI_SERVICE_URL")
 	if baseURL == "" {
 		baseURL = "http://localhost:5000" // Default to local development
 	}
 
-	return &AIServiceClient{
+	client := &AIServiceClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: time.Second * 30, // 30 second timeout
 		},
+		db:       db,
+		bulkhead: resilience.NewBulkhead(config.AIService.MaxConcurrentRequests),
+		retryConfig: resilience.RetryConfig{
+			MaxAttempts: config.AIService.RetryMaxAttempts,
+			BaseDelay:   config.AIService.RetryBaseDelay,
+			MaxDelay:    config.AIService.RetryMaxDelay,
+		},
 	}
+	client.breaker = resilience.NewCircuitBreaker(resilience.BreakerConfig{
+		FailureThreshold:    config.AIService.CircuitBreakerFailureThreshold,
+		OpenTimeout:         config.AIService.CircuitBreakerOpenTimeout,
+		HalfOpenMaxRequests: config.AIService.CircuitBreakerHalfOpenRequests,
+	}, client.recordBreakerTransition)
+
+	return client
 }
 
-// Chat sends a chat message to the AI service
+// recordBreakerTransition surfaces circuit breaker state changes as
+// SecurityEvent rows of type SYSTEM_ALERT, so ops sees AI outages in the
+// same audit surface as other incidents.
+func (c *AIServiceClient) recordBreakerTransition(from, to resilience.BreakerState) {
+	if c.db == nil {
+		return
+	}
+
+	event := &database.SecurityEvent{
+		EventType:   database.SecurityEventSystemAlert,
+		Severity:    database.SecuritySeverityHigh,
+		Description: fmt.Sprintf("AI service circuit breaker transitioned from %s to %s", from, to),
+		CreatedAt:   time.Now(),
+	}
+	if err := database.AppendSecurityEvent(c.db, event); err != nil {
+		log.Printf("Failed to record AI service circuit breaker transition: %v", err)
+	}
+}
+
+// Chat sends a chat message to the AI service. The call is bounded by a
+// bulkhead (limited concurrent in-flight requests), retried with backoff on
+// timeouts/5xx, and tracked by a circuit breaker that short-circuits with a
+// cached "unavailable" response once the service looks consistently down.
 func (c *AIServiceClient) Chat(req AIServiceRequest) (*AIServiceResponse, error) {
-	// Prepare request payload
+	ctx := context.Background()
+	if err := c.bulkhead.Acquire(ctx); err != nil {
+		return nil, fmt.Errorf("failed to acquire AI service capacity: %w", err)
+	}
+	defer c.bulkhead.Release()
+
+	var aiResp *AIServiceResponse
+	var callErr error
+
+	breakerErr := c.breaker.Execute(func() error {
+		err := resilience.Retry(ctx, c.retryConfig, isRetryableChatError, func() error {
+			resp, err := c.doChat(req)
+			aiResp = resp
+			callErr = err
+			return err
+		})
+		if err != nil && !isRetryableChatError(err) {
+			// The AI service responded - it just rejected this particular
+			// request (bad input, model-level error). That's not an
+			// infrastructure problem, so don't count it against the breaker.
+			return nil
+		}
+		return err
+	})
+
+	if errors.Is(breakerErr, resilience.ErrCircuitOpen) {
+		return &AIServiceResponse{
+			ThreadID: req.ThreadID,
+			Success:  false,
+			Error:    "AI service temporarily unavailable",
+			Details:  "circuit breaker is open after repeated failures; try again shortly",
+		}, breakerErr
+	}
+
+	return aiResp, callErr
+}
+
+// doChat performs a single, unretried round trip to /chat.
+func (c *AIServiceClient) doChat(req AIServiceRequest) (*AIServiceResponse, error) {
 	jsonData, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Create HTTP request
 	httpReq, err := http.NewRequest("POST", c.baseURL+"/chat", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -74,31 +195,32 @@ func (c *AIServiceClient) Chat(req AIServiceRequest) (*AIServiceResponse, error)
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("User-Agent", "HealthSecure-Go-Backend/1.0")
 
-	// Send request
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, &retryableError{fmt.Errorf("failed to send request: %w", err)}
 	}
 	defer resp.Body.Close()
 
-	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, &retryableError{fmt.Errorf("failed to read response: %w", err)}
+	}
+	if resp.StatusCode >= http.StatusInternalServerError && !json.Valid(body) {
+		return nil, &retryableError{fmt.Errorf("AI service error (status %d)", resp.StatusCode)}
 	}
 
-	// Parse response
 	var aiResp AIServiceResponse
 	if err := json.Unmarshal(body, &aiResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	// Check for HTTP errors
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return &aiResp, &retryableError{fmt.Errorf("AI service error (status %d): %s", resp.StatusCode, aiResp.Error)}
+	}
 	if resp.StatusCode != http.StatusOK {
 		return &aiResp, fmt.Errorf("AI service error (status %d): %s", resp.StatusCode, aiResp.Error)
 	}
 
-	// Check for application errors
 	if !aiResp.Success {
 		return &aiResp, fmt.Errorf("AI service failed: %s - %s", aiResp.Error, aiResp.Details)
 	}
@@ -106,38 +228,178 @@ func (c *AIServiceClient) Chat(req AIServiceRequest) (*AIServiceResponse, error)
 	return &aiResp, nil
 }
 
-// HealthCheck checks if the AI service is healthy
-func (c *AIServiceClient) HealthCheck() error {
-	resp, err := c.httpClient.Get(c.baseURL + "/health")
+// ChatStream sends a chat message to the AI service's streaming endpoint and
+// returns a channel of incremental AIChunk events. The returned channel is
+// closed once a "done" or "error" event is received, the upstream response
+// ends, or ctx is cancelled - so a caller can stop an in-flight stream by
+// cancelling ctx (e.g. when the client navigates away from the thread).
+func (c *AIServiceClient) ChatStream(ctx context.Context, req AIServiceRequest) (<-chan AIChunk, error) {
+	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return fmt.Errorf("health check failed: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/stream", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("User-Agent", "HealthSecure-Go-Backend/1.0")
+
+	// Streaming responses can run far longer than a normal request, so use a
+	// client without the 30s timeout that Chat relies on; ctx is what bounds
+	// this call instead.
+	streamClient := &http.Client{}
+
+	resp, err := streamClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("AI service unhealthy: status %d", resp.StatusCode)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("AI service error (status %d): %s", resp.StatusCode, string(body))
 	}
 
-	return nil
+	chunks := make(chan AIChunk)
+	go func() {
+		defer close(chunks)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+		for scanner.Scan() {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			line := scanner.Text()
+			data, ok := strings.CutPrefix(line, "data:")
+			if !ok {
+				continue // blank lines and any "event:" framing are ignored
+			}
+
+			var chunk AIChunk
+			if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &chunk); err != nil {
+				chunk = AIChunk{Type: "error", Error: fmt.Sprintf("failed to parse stream event: %v", err)}
+			}
+
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				return
+			}
+
+			if chunk.Type == "done" || chunk.Type == "error" {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			select {
+			case chunks <- AIChunk{Type: "error", Error: fmt.Sprintf("stream read failed: %v", err)}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return chunks, nil
 }
 
-// GetChatHistory retrieves chat history for a thread
+// HealthCheck checks if the AI service is healthy, retrying transient
+// failures since this is a cheap idempotent GET.
+func (c *AIServiceClient) HealthCheck() error {
+	return resilience.Retry(context.Background(), c.retryConfig, isRetryableChatError, func() error {
+		resp, err := c.httpClient.Get(c.baseURL + "/health")
+		if err != nil {
+			return &retryableError{fmt.Errorf("health check failed: %w", err)}
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return &retryableError{fmt.Errorf("AI service unhealthy: status %d", resp.StatusCode)}
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("AI service unhealthy: status %d", resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// GetChatHistory retrieves chat history for a thread, retrying transient
+// failures since this is an idempotent GET.
 func (c *AIServiceClient) GetChatHistory(threadID string) (map[string]interface{}, error) {
-	resp, err := c.httpClient.Get(fmt.Sprintf("%s/history/%s", c.baseURL, threadID))
+	var history map[string]interface{}
+
+	err := resilience.Retry(context.Background(), c.retryConfig, isRetryableChatError, func() error {
+		resp, err := c.httpClient.Get(fmt.Sprintf("%s/history/%s", c.baseURL, threadID))
+		if err != nil {
+			return &retryableError{fmt.Errorf("failed to get history: %w", err)}
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return &retryableError{fmt.Errorf("AI service error (status %d)", resp.StatusCode)}
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read history response: %w", err)
+		}
+
+		if err := json.Unmarshal(body, &history); err != nil {
+			return fmt.Errorf("failed to parse history: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get history: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	return history, nil
+}
+
+// SubmitFeedback posts thumbs-up/thumbs-down feedback for a run to the AI
+// service's LangSmith proxy, so the run is annotated in the tracing backend
+// itself rather than only recorded in our own database. comment and
+// correction may be empty.
+func (c *AIServiceClient) SubmitFeedback(runID, feedback, comment, correction string) error {
+	jsonData, err := json.Marshal(map[string]string{
+		"run_id":     runID,
+		"feedback":   feedback,
+		"comment":    comment,
+		"correction": correction,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read history response: %w", err)
+		return fmt.Errorf("failed to marshal feedback: %w", err)
 	}
 
-	var history map[string]interface{}
-	if err := json.Unmarshal(body, &history); err != nil {
-		return nil, fmt.Errorf("failed to parse history: %w", err)
-	}
+	return resilience.Retry(context.Background(), c.retryConfig, isRetryableChatError, func() error {
+		httpReq, err := http.NewRequest("POST", c.baseURL+"/feedback", bytes.NewBuffer(jsonData))
+		if err != nil {
+			return fmt.Errorf("failed to create feedback request: %w", err)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("User-Agent", "HealthSecure-Go-Backend/1.0")
 
-	return history, nil
-}
\ No newline at end of file
+		resp, err := c.httpClient.Do(httpReq)
+		if err != nil {
+			return &retryableError{fmt.Errorf("failed to send feedback: %w", err)}
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return &retryableError{fmt.Errorf("AI service feedback error (status %d)", resp.StatusCode)}
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("AI service feedback error (status %d)", resp.StatusCode)
+		}
+		return nil
+	})
+}
@@ -1,38 +1,53 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
 	"healthsecure/internal/auth"
 	"healthsecure/internal/models"
 	"healthsecure/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/webauthn"
 )
 
 type SignupRequest struct {
-	Email    string           `json:"email" binding:"required,email"`
-	Password string           `json:"password" binding:"required,min=8"`
-	Name     string           `json:"name" binding:"required"`
-	Role     models.UserRole  `json:"role" binding:"required,oneof=doctor nurse"`
+	Email       string          `json:"email" binding:"required,email"`
+	Password    string          `json:"password"`
+	PasskeyOnly bool            `json:"passkey_only"`
+	Name        string          `json:"name" binding:"required"`
+	Role        models.UserRole `json:"role" binding:"required,oneof=doctor nurse"`
 }
 
 type AuthHandler struct {
-	userService  *services.UserService
-	oauthService *auth.OAuthService
-	jwtService   *auth.JWTService
+	userService   *services.UserService
+	oauthService  *auth.OAuthService
+	jwtService    *auth.JWTService
+	webAuthn      *auth.WebAuthnService
+	ceremonies    *webAuthnSignupCeremonyStore
+	loginAttempts *services.LoginAttemptTracker
 }
 
-func NewAuthHandler(userService *services.UserService, oauthService *auth.OAuthService, jwtService *auth.JWTService) *AuthHandler {
+func NewAuthHandler(userService *services.UserService, oauthService *auth.OAuthService, jwtService *auth.JWTService, webAuthn *auth.WebAuthnService, loginAttempts *services.LoginAttemptTracker) *AuthHandler {
 	return &AuthHandler{
-		userService:  userService,
-		oauthService: oauthService,
-		jwtService:   jwtService,
+		userService:   userService,
+		oauthService:  oauthService,
+		jwtService:    jwtService,
+		webAuthn:      webAuthn,
+		ceremonies:    newWebAuthnSignupCeremonyStore(),
+		loginAttempts: loginAttempts,
 	}
 }
 
-// Login handles user authentication
+// Login handles user authentication. Before checking credentials it enforces
+// a per-IP exponential backoff (429) across every account, and a per-account
+// lockout (423) once the same account has failed too many times in a row;
+// see services.LoginAttemptTracker for both thresholds.
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req services.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -43,8 +58,32 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	ipAddress := c.ClientIP()
 	userAgent := c.GetHeader("User-Agent")
 
+	if blocked, retryAfter, err := h.loginAttempts.CheckIPRateLimit(ipAddress); err == nil && blocked {
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many failed login attempts from this address"})
+		return
+	}
+
+	user, _ := h.userService.GetUserByEmail(req.Email)
+	var userID *uint
+	if user != nil {
+		userID = &user.ID
+		if locked, err := h.loginAttempts.CheckUserLockout(user.ID); err == nil && locked {
+			c.JSON(http.StatusLocked, gin.H{"error": "account locked due to too many failed login attempts"})
+			return
+		}
+	}
+
 	tokens, err := h.userService.Login(&req, ipAddress, userAgent)
+	_ = h.loginAttempts.RecordAttempt(userID, ipAddress, userAgent, err == nil)
+
 	if err != nil {
+		if userID != nil {
+			if locked, lockErr := h.loginAttempts.CheckUserLockout(*userID); lockErr == nil && locked {
+				c.JSON(http.StatusLocked, gin.H{"error": "account locked due to too many failed login attempts"})
+				return
+			}
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
@@ -59,7 +98,13 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	})
 }
 
-// Signup handles user registration
+// Signup handles user registration. A request either supplies password (at
+// least 8 characters) or sets passkey_only=true, in which case the account
+// is created with no usable password and the caller is expected to follow up
+// with BeginRegistration/FinishRegistration to enroll its first passkey
+// before it can ever log in. Mount behind internal/middleware.IdempotencyMiddleware
+// so a retried submission (network retry, double-click) can't fail with a
+// confusing "email already registered" on its second attempt.
 func (h *AuthHandler) Signup(c *gin.Context) {
 	var req SignupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -67,10 +112,27 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 		return
 	}
 
-	// Convert to CreateUserRequest
+	if !req.PasskeyOnly && len(req.Password) < 8 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "password must be at least 8 characters, or set passkey_only"})
+		return
+	}
+
+	password := req.Password
+	if req.PasskeyOnly {
+		// users.password is NOT NULL; a passkey-only account gets a random
+		// value nobody is ever told, so password login can never succeed
+		// for it.
+		randomPassword, err := randomToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+			return
+		}
+		password = randomPassword
+	}
+
 	createReq := &services.CreateUserRequest{
 		Email:    req.Email,
-		Password: req.Password,
+		Password: password,
 		Name:     req.Name,
 		Role:     req.Role,
 	}
@@ -82,6 +144,28 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 		return
 	}
 
+	if req.PasskeyOnly {
+		session, options, err := h.webAuthn.BeginRegistration(user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		token, err := h.ceremonies.put(*session, user.ID, webAuthnCeremonyRegistration)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start registration ceremony"})
+			return
+		}
+
+		c.SetCookie(webAuthnSignupCeremonyCookie, token, int(webAuthnSignupCeremonyTTL.Seconds()), "/", "", true, true)
+		c.JSON(http.StatusCreated, gin.H{
+			"message":                     "Account created. Complete passkey registration to finish signing up.",
+			"user":                        user,
+			"credential_creation_options": options,
+		})
+		return
+	}
+
 	// Auto-login the new user
 	loginReq := &services.LoginRequest{
 		Email:    req.Email,
@@ -111,7 +195,256 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 	})
 }
 
-// RefreshToken handles token refresh
+const webAuthnSignupCeremonyCookie = "hs_signup_webauthn_ceremony"
+const webAuthnSignupCeremonyTTL = 5 * time.Minute
+
+// webAuthnCeremonyKind records which ceremony a stored SessionData was
+// opened for. go-webauthn uses the same SessionData struct for both
+// registration and login, and a client fully controls the challenge it
+// signs when calling navigator.credentials.create() - so without this tag,
+// a ceremony opened by BeginLogin (which only requires knowing a victim's
+// email) could be redeemed at FinishRegistration to enroll an attacker's
+// own credential on the victim's account.
+type webAuthnCeremonyKind string
+
+const (
+	webAuthnCeremonyRegistration webAuthnCeremonyKind = "registration"
+	webAuthnCeremonyLogin        webAuthnCeremonyKind = "login"
+)
+
+// webAuthnSignupCeremony pairs a registration/login SessionData with the
+// user it belongs to and the kind of ceremony it was opened for, since -
+// unlike MFAHandler's step-up ceremonies - Signup/BeginLogin run before the
+// caller holds a JWT, so there is no authenticated user_id in context to
+// fall back on between Begin* and Finish*.
+type webAuthnSignupCeremony struct {
+	session webauthn.SessionData
+	userID  uint
+	kind    webAuthnCeremonyKind
+}
+
+type webAuthnSignupCeremonyStore struct {
+	mu         sync.Mutex
+	ceremonies map[string]webAuthnSignupCeremony
+	expiresAt  map[string]time.Time
+}
+
+func newWebAuthnSignupCeremonyStore() *webAuthnSignupCeremonyStore {
+	return &webAuthnSignupCeremonyStore{
+		ceremonies: make(map[string]webAuthnSignupCeremony),
+		expiresAt:  make(map[string]time.Time),
+	}
+}
+
+func (s *webAuthnSignupCeremonyStore) put(session webauthn.SessionData, userID uint, kind webAuthnCeremonyKind) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ceremonies[token] = webAuthnSignupCeremony{session: session, userID: userID, kind: kind}
+	s.expiresAt[token] = time.Now().Add(webAuthnSignupCeremonyTTL)
+	return token, nil
+}
+
+func (s *webAuthnSignupCeremonyStore) take(token string) (webAuthnSignupCeremony, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ceremony, ok := s.ceremonies[token]
+	if !ok || time.Now().After(s.expiresAt[token]) {
+		delete(s.ceremonies, token)
+		delete(s.expiresAt, token)
+		return webAuthnSignupCeremony{}, false
+	}
+
+	delete(s.ceremonies, token)
+	delete(s.expiresAt, token)
+	return ceremony, true
+}
+
+func randomToken() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// FinishRegistration verifies the passkey created in response to Signup's
+// (or BeginRegistration's) credential_creation_options and, on success, logs
+// the new account in the same way Login does.
+func (h *AuthHandler) FinishRegistration(c *gin.Context) {
+	token, err := c.Cookie(webAuthnSignupCeremonyCookie)
+	if err != nil || token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No pending passkey registration"})
+		return
+	}
+
+	ceremony, ok := h.ceremonies.take(token)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Passkey registration ceremony expired or not found"})
+		return
+	}
+
+	if ceremony.kind != webAuthnCeremonyRegistration {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Passkey registration ceremony expired or not found"})
+		return
+	}
+
+	user, err := h.userService.GetUser(ceremony.userID, ceremony.userID, models.RoleAdmin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load account"})
+		return
+	}
+
+	if err := h.webAuthn.FinishRegistration(user, ceremony.session, c.Request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ipAddress := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+	tokens, err := h.userService.IssueTokens(user, ipAddress, userAgent)
+	if err != nil {
+		c.JSON(http.StatusCreated, gin.H{
+			"message": "Passkey registered. Please log in.",
+			"user":    user,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Passkey registered and logged in successfully",
+		"token":         tokens.AccessToken,
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_at":    tokens.ExpiresAt,
+		"user":          tokens.User,
+	})
+}
+
+type beginWebAuthnLoginRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// BeginLogin starts a passkey assertion ceremony for a returning user,
+// identified by email the same way Login is. An unknown email gets 401. For an
+// account with no enrolled passkey (such as a legacy, password-only one),
+// WebAuthnService.BeginLogin returns an error, which this handler reports as
+// a 500.
+func (h *AuthHandler) BeginLogin(c *gin.Context) {
+	var req beginWebAuthnLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.GetUserByEmail(req.Email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+
+	session, options, err := h.webAuthn.BeginLogin(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.ceremonies.put(*session, user.ID, webAuthnCeremonyLogin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login ceremony"})
+		return
+	}
+
+	c.SetCookie(webAuthnSignupCeremonyCookie, token, int(webAuthnSignupCeremonyTTL.Seconds()), "/", "", true, true)
+	c.JSON(http.StatusOK, gin.H{"credential_request_options": options})
+}
+
+// FinishLogin verifies the passkey assertion started by BeginLogin and
+// issues the same tokens payload Login returns.
+func (h *AuthHandler) FinishLogin(c *gin.Context) {
+	token, err := c.Cookie(webAuthnSignupCeremonyCookie)
+	if err != nil || token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No pending passkey login"})
+		return
+	}
+
+	ceremony, ok := h.ceremonies.take(token)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Passkey login ceremony expired or not found"})
+		return
+	}
+
+	if ceremony.kind != webAuthnCeremonyLogin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Passkey login ceremony expired or not found"})
+		return
+	}
+
+	user, err := h.userService.GetUser(ceremony.userID, ceremony.userID, models.RoleAdmin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load account"})
+		return
+	}
+
+	if err := h.webAuthn.FinishLogin(user, ceremony.session, c.Request); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ipAddress := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+	tokens, err := h.userService.IssueTokens(user, ipAddress, userAgent)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Login successful",
+		"token":         tokens.AccessToken,
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_at":    tokens.ExpiresAt,
+		"user":          tokens.User,
+	})
+}
+
+// BeginRegistration starts a passkey-creation ceremony for an already signed
+// in user who wants to add another passkey (e.g. a second device), using the
+// same ceremony store Signup's passkey_only path uses.
+func (h *AuthHandler) BeginRegistration(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	userRole := models.UserRole(c.GetString("user_role"))
+
+	user, err := h.userService.GetUser(userID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return
+	}
+
+	session, options, err := h.webAuthn.BeginRegistration(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.ceremonies.put(*session, user.ID, webAuthnCeremonyRegistration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start registration ceremony"})
+		return
+	}
+
+	c.SetCookie(webAuthnSignupCeremonyCookie, token, int(webAuthnSignupCeremonyTTL.Seconds()), "/", "", true, true)
+	c.JSON(http.StatusOK, gin.H{"credential_creation_options": options})
+}
+
+// RefreshToken handles token refresh. Mount behind
+// internal/middleware.IdempotencyMiddleware so a retried refresh can't race
+// itself into rotating the refresh token twice.
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req struct {
 		RefreshToken string `json:"refresh_token" binding:"required"`
@@ -143,7 +476,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	authHeader := c.GetHeader("Authorization")
 	accessToken := auth.ExtractTokenFromHeader(authHeader)
-	
+
 	ipAddress := c.ClientIP()
 	userAgent := c.GetHeader("User-Agent")
 
@@ -192,10 +525,13 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	})
 }
 
-// ChangePassword handles password changes
+// ChangePassword handles password changes. Mount behind
+// internal/middleware.IdempotencyMiddleware so a retried submission can't
+// surface as a confusing second failure once the first attempt already
+// succeeded.
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	
+
 	var req services.ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -227,32 +563,58 @@ func (h *AuthHandler) GetUserSessions(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
 }
 
-// OAuthLogin initiates OAuth login flow
-func (h *AuthHandler) OAuthLogin(c *gin.Context) {
+// SSOLogin handles GET /auth/sso/:provider/login, starting the
+// authorization_code flow against the named identity provider (google,
+// microsoft, okta, or a hospital's configured generic OIDC provider).
+func (h *AuthHandler) SSOLogin(c *gin.Context) {
 	provider := c.Param("provider")
-	
-	authURL, err := h.oauthService.GenerateAuthURL(auth.OAuthProvider(provider))
+
+	authURL, state, err := h.oauthService.GenerateAuthURL(provider)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Bind state to this browser so SSOCallback can tell a legitimate
+	// completion of this flow apart from an attacker replaying their own
+	// valid (code, state) pair to hijack a victim's session (RFC 6749
+	// §10.12 login CSRF).
+	c.SetCookie(ssoStateCookie, state, int(ssoStateCookieTTL.Seconds()), "/", "", true, true)
+
 	c.JSON(http.StatusOK, gin.H{
 		"auth_url": authURL,
 		"provider": provider,
 	})
 }
 
-// OAuthCallback handles OAuth callback
-func (h *AuthHandler) OAuthCallback(c *gin.Context) {
-	tokens, err := h.oauthService.HandleCallback(c)
+const ssoStateCookie = "hs_sso_state"
+const ssoStateCookieTTL = 10 * time.Minute
+
+// SSOCallback handles GET /auth/sso/:provider/callback. It requires the
+// state query parameter to match the hs_sso_state cookie SSOLogin set
+// before exchanging the authorization code, finds-or-links the HealthSecure
+// user the identity belongs to, and returns tokens the same shape as a
+// password login.
+func (h *AuthHandler) SSOCallback(c *gin.Context) {
+	provider := c.Param("provider")
+	code := c.Query("code")
+	state := c.Query("state")
+
+	cookieState, err := c.Cookie(ssoStateCookie)
+	if err != nil || state == "" || cookieState != state {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or missing OAuth state"})
+		return
+	}
+	c.SetCookie(ssoStateCookie, "", -1, "/", "", true, true)
+
+	tokens, err := h.oauthService.HandleCallback(provider, code, state)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":       "OAuth login successful",
+		"message":       "SSO login successful",
 		"token":         tokens.AccessToken,
 		"access_token":  tokens.AccessToken,
 		"refresh_token": tokens.RefreshToken,
@@ -267,7 +629,7 @@ func (h *AuthHandler) OAuthCallback(c *gin.Context) {
 func (h *AuthHandler) ValidateToken(c *gin.Context) {
 	authHeader := c.GetHeader("Authorization")
 	token := auth.ExtractTokenFromHeader(authHeader)
-	
+
 	if token == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
 		return
@@ -283,13 +645,81 @@ func (h *AuthHandler) ValidateToken(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"valid":    true,
-		"user_id":  claims.UserID,
-		"role":     claims.Role,
-		"expires":  claims.ExpiresAt,
+		"valid":   true,
+		"user_id": claims.UserID,
+		"role":    claims.Role,
+		"expires": claims.ExpiresAt,
 	})
 }
 
+// ValidatePeerCert is ValidateToken's companion for mTLS callers: it reports
+// the identity internal/auth.MTLSMiddleware already resolved for the current
+// request's client certificate, so an operator can debug a device's
+// connection without needing a separate certificate inspection tool.
+func (h *AuthHandler) ValidatePeerCert(c *gin.Context) {
+	if c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": "no client certificate presented"})
+		return
+	}
+
+	userID, hasUserID := c.Get("user_id")
+	if !hasUserID {
+		c.JSON(http.StatusUnauthorized, gin.H{"valid": false, "error": "certificate did not resolve to a known device identity"})
+		return
+	}
+
+	cert := c.Request.TLS.PeerCertificates[0]
+	c.JSON(http.StatusOK, gin.H{
+		"valid":              true,
+		"user_id":            userID,
+		"user_role":          c.GetString("user_role"),
+		"device_identity_id": c.GetUint("device_identity_id"),
+		"common_name":        cert.Subject.CommonName,
+		"fingerprint":        auth.CertFingerprint(cert),
+		"not_after":          cert.NotAfter,
+	})
+}
+
+// ListLoginAttempts handles GET /admin/login-attempts?user_id=&ip=, letting
+// security teams investigate a lockout or an in-progress brute-force attempt.
+func (h *AuthHandler) ListLoginAttempts(c *gin.Context) {
+	var userID *uint
+	if raw := c.Query("user_id"); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+		id := uint(parsed)
+		userID = &id
+	}
+
+	attempts, err := h.loginAttempts.ListAttempts(userID, c.Query("ip"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list login attempts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attempts": attempts})
+}
+
+// UnlockUser handles POST /admin/users/:id/unlock, clearing the lockout
+// LoginAttemptTracker placed on the account.
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	if err := h.loginAttempts.Unlock(uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlocked"})
+}
+
 // GetSupportedOAuthProviders returns available OAuth providers
 func (h *AuthHandler) GetSupportedOAuthProviders(c *gin.Context) {
 	if !h.oauthService.IsConfigured() {
@@ -336,4 +766,4 @@ func getPaginationParams(c *gin.Context) (page int, limit int) {
 	}
 
 	return page, limit
-}
\ No newline at end of file
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -59,7 +60,7 @@ func (m *MockAuditService) LogAction(log *models.AuditLog) error {
 	return args.Error(0)
 }
 
-func setupAuthHandler(t *testing.T) (*AuthHandler, *MockUserService, *MockAuditService, *auth.JWTService) {
+func setupAuthHandler(t *testing.T) (*AuthHandler, *MockUserService, *MockAuditService, *auth.JWTService, *auth.OAuthService) {
 	gin.SetMode(gin.TestMode)
 
 	config := &configs.Config{
@@ -92,17 +93,17 @@ func setupAuthHandler(t *testing.T) (*AuthHandler, *MockUserService, *MockAuditS
 	jwtService := auth.NewJWTService(config)
 	mockUserService := &MockUserService{}
 	mockAuditService := &MockAuditService{}
-	oauthService := auth.NewOAuthService(config)
+	oauthService := auth.NewOAuthService(config, database.GetDB(), jwtService)
 
 	realUserService := services.NewUserService(database.GetDB(), jwtService, nil)
 
 	handler := NewAuthHandler(realUserService, oauthService, jwtService)
 
-	return handler, mockUserService, mockAuditService, jwtService
+	return handler, mockUserService, mockAuditService, jwtService, oauthService
 }
 
 func TestAuthHandler_Login(t *testing.T) {
-	handler, _, _, _ := setupAuthHandler(t)
+	handler, _, _, _, _ := setupAuthHandler(t)
 	defer database.Close()
 
 	t.Run("SuccessfulLogin", func(t *testing.T) {
@@ -184,8 +185,94 @@ func TestAuthHandler_Login(t *testing.T) {
 	})
 }
 
+type mockSSOProvider struct {
+	name string
+	info auth.UserInfo
+	err  error
+}
+
+func (p *mockSSOProvider) Name() string { return p.name }
+func (p *mockSSOProvider) AuthCodeURL(state string) string {
+	return "https://idp.example/authorize?state=" + state
+}
+func (p *mockSSOProvider) Exchange(code string) (auth.UserInfo, error) {
+	return p.info, p.err
+}
+
+func TestAuthHandler_SSOCallback(t *testing.T) {
+	cases := []struct {
+		name       string
+		provider   mockSSOProvider
+		wantStatus int
+		wantRole   models.UserRole
+	}{
+		{
+			name: "NewUserIsProvisionedWithMappedRole",
+			provider: mockSSOProvider{
+				name: "google",
+				info: auth.UserInfo{Subject: "google-sub-1", Email: "new.doctor@hospital.local", Name: "New Doctor", Groups: []string{"radiology-attending"}},
+			},
+			wantStatus: http.StatusOK,
+			wantRole:   models.RoleDoctor,
+		},
+		{
+			name: "UnmappedGroupIsRejected",
+			provider: mockSSOProvider{
+				name: "google",
+				info: auth.UserInfo{Subject: "google-sub-2", Email: "unmapped@hospital.local", Name: "Nobody", Groups: []string{"catering-staff"}},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "ExchangeFailureIsSurfaced",
+			provider: mockSSOProvider{
+				name: "google",
+				err:  fmt.Errorf("idp unreachable"),
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler, _, _, _, oauthService := setupAuthHandler(t)
+			defer database.Close()
+
+			oauthService.Register(&tc.provider)
+			_, state, err := oauthService.GenerateAuthURL(tc.provider.name)
+			require.NoError(t, err)
+
+			router := gin.New()
+			router.GET("/auth/sso/:provider/callback", handler.SSOCallback)
+
+			req := httptest.NewRequest("GET", "/auth/sso/google/callback?code=test-code&state="+state, nil)
+			req.AddCookie(&http.Cookie{Name: ssoStateCookie, Value: state})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.NotEmpty(t, response["access_token"])
+
+			var identity models.UserIdentity
+			require.NoError(t, database.GetDB().Where("provider = ? AND subject = ?", tc.provider.name, tc.provider.info.Subject).First(&identity).Error)
+
+			var user models.User
+			require.NoError(t, database.GetDB().First(&user, identity.UserID).Error)
+			assert.Equal(t, tc.wantRole, user.Role)
+			assert.Equal(t, tc.provider.info.Email, user.Email)
+		})
+	}
+}
+
 func TestAuthHandler_RefreshToken(t *testing.T) {
-	handler, _, _, jwtService := setupAuthHandler(t)
+	handler, _, _, jwtService, _ := setupAuthHandler(t)
 	defer database.Close()
 
 	t.Run("SuccessfulRefresh", func(t *testing.T) {
@@ -248,7 +335,7 @@ func TestAuthHandler_RefreshToken(t *testing.T) {
 }
 
 func TestAuthHandler_Logout(t *testing.T) {
-	handler, _, _, jwtService := setupAuthHandler(t)
+	handler, _, _, jwtService, _ := setupAuthHandler(t)
 	defer database.Close()
 
 	user := &models.User{
@@ -281,7 +368,7 @@ func TestAuthHandler_Logout(t *testing.T) {
 }
 
 func TestAuthHandler_ChangePassword(t *testing.T) {
-	handler, _, _, jwtService := setupAuthHandler(t)
+	handler, _, _, jwtService, _ := setupAuthHandler(t)
 	defer database.Close()
 
 	user := &models.User{
@@ -341,4 +428,4 @@ func TestAuthHandler_ChangePassword(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
-}
\ No newline at end of file
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -8,12 +10,17 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"healthsecure/configs"
 	"healthsecure/internal/auth"
+	"healthsecure/internal/chatcommands"
+	"healthsecure/internal/errs"
 	"healthsecure/internal/models"
 	"healthsecure/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // Sanitize input to remove potential HTML tags
@@ -24,12 +31,14 @@ func sanitizeInput(input string) string {
 }
 
 type ChatHandler struct {
-	userService       *services.UserService
-	patientService    *services.PatientService
-	auditService      *services.AuditService
-	chatThreadService *services.ChatThreadService
-	jwtService        *auth.JWTService
-	aiServiceClient   *AIServiceClient
+	userService            *services.UserService
+	patientService         *services.PatientService
+	auditService           *services.AuditService
+	chatThreadService      *services.ChatThreadService
+	emergencyAccessService *services.EmergencyAccessService
+	jwtService             *auth.JWTService
+	aiServiceClient        *AIServiceClient
+	commandRegistry        *chatcommands.Registry
 }
 
 type ChatConfig struct {
@@ -54,8 +63,10 @@ type ThreadRequest struct {
 }
 
 type FeedbackRequest struct {
-	MessageID string `json:"message_id" binding:"required"`
-	Feedback  string `json:"feedback" binding:"required,oneof=thumbs_up thumbs_down"`
+	MessageID  string `json:"message_id" binding:"required"`
+	Feedback   string `json:"feedback" binding:"required,oneof=thumbs_up thumbs_down"`
+	Comment    string `json:"comment"`
+	Correction string `json:"correction"`
 }
 
 type ChatResponse struct {
@@ -68,14 +79,25 @@ type ChatResponse struct {
 	Data      map[string]interface{} `json:"data,omitempty"`
 }
 
-func NewChatHandler(userService *services.UserService, patientService *services.PatientService, auditService *services.AuditService, chatThreadService *services.ChatThreadService, jwtService *auth.JWTService) *ChatHandler {
+func NewChatHandler(db *gorm.DB, config *configs.Config, userService *services.UserService, patientService *services.PatientService, auditService *services.AuditService, chatThreadService *services.ChatThreadService, emergencyAccessService *services.EmergencyAccessService, jwtService *auth.JWTService) *ChatHandler {
+	// Start from the default commands, then replace the no-op
+	// emergency_access with one backed by a live EmergencyAccessService, so
+	// the chat "emergency" intent actually opens a break-glass grant.
+	registry := chatcommands.NewRegistry()
+	for _, cmd := range chatcommands.DefaultCommands() {
+		registry.Register(cmd)
+	}
+	registry.Register(chatcommands.NewEmergencyAccessCommand(emergencyAccessService))
+
 	return &ChatHandler{
-		userService:       userService,
-		patientService:    patientService,
-		auditService:      auditService,
-		chatThreadService: chatThreadService,
-		jwtService:        jwtService,
-		aiServiceClient:   NewAIServiceClient(),
+		userService:            userService,
+		patientService:         patientService,
+		auditService:           auditService,
+		chatThreadService:      chatThreadService,
+		emergencyAccessService: emergencyAccessService,
+		jwtService:             jwtService,
+		aiServiceClient:        NewAIServiceClient(db, config),
+		commandRegistry:        registry,
 	}
 }
 
@@ -94,9 +116,7 @@ func (h *ChatHandler) ProcessChatMessage(c *gin.Context) {
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Printf("ERROR: JSON binding failed: %v", err)
 		log.Printf("Request body that failed: %s", string(bodyBytes))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("Invalid request format: %v", err),
-		})
+		c.Error(errs.New(errs.ErrValidationFailed, fmt.Sprintf("invalid request format: %v", err)))
 		return
 	}
 
@@ -108,9 +128,7 @@ func (h *ChatHandler) ProcessChatMessage(c *gin.Context) {
 	// Get current user from JWT token
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		c.Error(errs.New(errs.ErrUnauthenticated, "unauthorized"))
 		return
 	}
 
@@ -122,20 +140,15 @@ func (h *ChatHandler) ProcessChatMessage(c *gin.Context) {
 		thread, err := h.chatThreadService.CreateThread(userIDStr, "New Chat")
 		if err != nil {
 			log.Printf("Failed to create thread: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to create chat thread",
-			})
+			c.Error(errs.Wrap(errs.ErrInternal, err, "failed to create chat thread"))
 			return
 		}
 		threadID = thread.ThreadID
 	} else {
 		threadID = req.Config.Configurable.ThreadID
 		// Verify thread exists and belongs to user
-		_, err := h.chatThreadService.GetThread(threadID, userIDStr)
-		if err != nil {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Invalid or inaccessible thread ID",
-			})
+		if _, err := h.chatThreadService.GetThread(threadID, userIDStr); err != nil {
+			c.Error(errs.New(errs.ErrNoPermission, "invalid or inaccessible thread ID"))
 			return
 		}
 	}
@@ -159,19 +172,10 @@ func (h *ChatHandler) ProcessChatMessage(c *gin.Context) {
 	)
 
 	// Process the message through AI service with thread context
-	response, err := h.processChatMessageWithAIService(req, userIDStr, threadID)
+	response, err := h.processChatMessageWithAIService(c.Request.Context(), req, userIDStr, threadID)
 	if err != nil {
 		log.Printf("AI service processing error: %v", err)
-		// Check for specific AI service configuration error
-		if strings.Contains(err.Error(), "AI service is not configured") {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "The AI assistant is not configured on the server. Please contact the administrator.",
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to get response from AI assistant",
-			})
-		}
+		c.Error(err)
 		return
 	}
 
@@ -196,64 +200,20 @@ func (h *ChatHandler) ProcessChatMessage(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-func (h *ChatHandler) processChatMessageWithAIService(req ChatRequest, userID, threadID string) (*ChatResponse, error) {
+func (h *ChatHandler) processChatMessageWithAIService(ctx context.Context, req ChatRequest, userID, threadID string) (*ChatResponse, error) {
 	log.Printf("processChatMessageWithAIService called - UserID: %s, ThreadID: %s, Message: %s", userID, threadID, req.Message)
-	
+
 	// First, test AI service health
 	if err := h.aiServiceClient.HealthCheck(); err != nil {
 		log.Printf("AI service health check failed: %v", err)
-		return nil, fmt.Errorf("AI service unavailable: %w", err)
+		return nil, errs.Wrap(errs.ErrExternal, err, "the AI assistant is unavailable, please try again shortly")
 	}
-	
+
 	log.Printf("AI service health check passed")
-	
-	// Get user info from database if not provided in request
-	userRole := req.User.Role
-	userName := req.User.Name
-	
-	if userRole == "" || userName == "" {
-		// Fallback: get user info from database
-		userIDUint, _ := strconv.ParseUint(userID, 10, 32)
-		// Use the user's own role or admin to avoid permission issues
-		var roleForQuery models.UserRole = models.RoleAdmin
-		if userRole != "" {
-			roleForQuery = models.UserRole(userRole)
-		}
-		
-		user, err := h.userService.GetUser(uint(userIDUint), uint(userIDUint), roleForQuery)
-		if err == nil {
-			if userRole == "" {
-				userRole = string(user.Role)
-			}
-			if userName == "" {
-				userName = user.Name
-			}
-		} else {
-			log.Printf("Warning: Could not get user info from database: %v", err)
-			// Set fallback values if database query fails
-			if userRole == "" {
-				userRole = "doctor"
-			}
-			if userName == "" {
-				userName = "Unknown User"
-			}
-		}
-	}
 
-	// Get chat history
-	history, err := h.chatThreadService.GetThreadMessages(threadID, userID, 10) // Get last 10 messages
+	aiReq, err := h.buildAIServiceRequest(req, userID, threadID)
 	if err != nil {
-		log.Printf("Warning: Could not get chat history: %v", err)
-	}
-	
-	// Prepare request for Python AI service
-	aiReq := AIServiceRequest{
-		Message:  req.Message,
-		ThreadID: threadID,
-		UserID:   userID,
-		UserRole: userRole,
-		UserName: userName,
-		History:  history,
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to prepare AI request")
 	}
 
 	log.Printf("Calling Python AI service with request: %+v", aiReq)
@@ -262,9 +222,9 @@ func (h *ChatHandler) processChatMessageWithAIService(req ChatRequest, userID, t
 	aiResp, err := h.aiServiceClient.Chat(aiReq)
 	if err != nil {
 		log.Printf("AI service error: %v", err)
-		return nil, fmt.Errorf("failed to get AI response: %w", err)
+		return nil, errs.Wrap(errs.ErrExternal, err, "failed to get a response from the AI assistant")
 	}
-	
+
 	log.Printf("Python AI service response received: %+v", aiResp)
 
 	// Create response object
@@ -284,32 +244,244 @@ func (h *ChatHandler) processChatMessageWithAIService(req ChatRequest, userID, t
 	}
 
 	// Handle special commands or data queries (optional - could move to Python service)
-	if err := h.processSpecialCommands(req.Message, req.User.Role, response); err != nil {
+	userIDUint, _ := strconv.ParseUint(userID, 10, 32)
+	if err := h.processSpecialCommands(ctx, req.Message, req.User.Role, uint(userIDUint), response); err != nil {
 		log.Printf("Error processing special commands: %v", err)
 	}
-	
+
 	// Note: Message history is now handled by the Python AI service
-	
+
 	log.Printf("AI response generated successfully - Run ID: %s", aiResp.RunID)
 
 	return response, nil
 }
 
+// StreamChatMessage proxies a streamed AI response to the browser as
+// Server-Sent Events instead of blocking until the full answer is ready.
+// The request body and thread handling mirror ProcessChatMessage; only the
+// response transport differs.
+func (h *ChatHandler) StreamChatMessage(c *gin.Context) {
+	var req ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.New(errs.ErrValidationFailed, fmt.Sprintf("invalid request format: %v", err)))
+		return
+	}
+
+	req.Message = sanitizeInput(req.Message)
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(errs.New(errs.ErrUnauthenticated, "unauthorized"))
+		return
+	}
+	userIDStr := fmt.Sprintf("%v", userID)
+
+	var threadID string
+	if strings.HasPrefix(req.Config.Configurable.ThreadID, "temp_") || req.Config.Configurable.ThreadID == "" {
+		thread, err := h.chatThreadService.CreateThread(userIDStr, "New Chat")
+		if err != nil {
+			c.Error(errs.Wrap(errs.ErrInternal, err, "failed to create chat thread"))
+			return
+		}
+		threadID = thread.ThreadID
+	} else {
+		threadID = req.Config.Configurable.ThreadID
+		if _, err := h.chatThreadService.GetThread(threadID, userIDStr); err != nil {
+			c.Error(errs.New(errs.ErrNoPermission, "invalid or inaccessible thread ID"))
+			return
+		}
+	}
+
+	if _, err := h.chatThreadService.SaveMessage(threadID, "user", req.Message, ""); err != nil {
+		log.Printf("Failed to save user message: %v", err)
+	}
+
+	userIDUint, _ := strconv.ParseUint(userIDStr, 10, 32)
+	h.auditService.LogUserAction(
+		uint(userIDUint),
+		models.ActionCreate,
+		fmt.Sprintf("chat_message:%s", threadID),
+		c.ClientIP(),
+		c.Request.UserAgent(),
+		true,
+		fmt.Sprintf("User sent message in thread %s", threadID),
+	)
+
+	aiReq, err := h.buildAIServiceRequest(req, userIDStr, threadID)
+	if err != nil {
+		c.Error(errs.Wrap(errs.ErrInternal, err, "failed to prepare AI request"))
+		return
+	}
+
+	// ctx is tied to the client connection: if the browser navigates away,
+	// gin cancels the request context, ChatStream's HTTP call is aborted,
+	// and the goroutine reading the upstream response exits.
+	chunks, err := h.aiServiceClient.ChatStream(c.Request.Context(), aiReq)
+	if err != nil {
+		c.Error(errs.Wrap(errs.ErrExternal, err, "failed to start AI response stream"))
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // disable nginx buffering of the stream
+
+	flusher, canFlush := c.Writer.(http.Flusher)
+
+	var fullResponse strings.Builder
+	var runID, modelUsed, newTitle, streamErr string
+	var tokensUsed *int
+
+	for chunk := range chunks {
+		switch chunk.Type {
+		case "token":
+			fullResponse.WriteString(chunk.Content)
+		case "done":
+			runID = chunk.RunID
+			modelUsed = chunk.ModelUsed
+			newTitle = chunk.NewTitle
+			tokensUsed = chunk.TokensUsed
+		case "error":
+			streamErr = chunk.Error
+		}
+
+		payload, err := json.Marshal(chunk)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", chunk.Type, payload)
+		if canFlush {
+			flusher.Flush()
+		}
+
+		if chunk.Type == "error" {
+			break
+		}
+	}
+
+	// The loop above exits because the upstream stream finished normally,
+	// the client disconnected and cancelled c.Request.Context() (ChatStream
+	// then closes chunks), or an "error" chunk terminated it early. Either
+	// way we get here at most once, so the assistant turn - even a partial
+	// one cut short by an upstream error - is saved and audited exactly
+	// once instead of silently dropped.
+	assistantMessage, err := h.chatThreadService.SaveMessage(threadID, "assistant", fullResponse.String(), runID)
+	if err != nil {
+		log.Printf("Failed to save assistant message: %v", err)
+		return
+	}
+
+	auditDetail := fmt.Sprintf("Streamed assistant response in thread %s", threadID)
+	if streamErr != "" {
+		auditDetail = fmt.Sprintf("Streamed assistant response in thread %s terminated early: %s", threadID, streamErr)
+	}
+	h.auditService.LogUserAction(
+		uint(userIDUint),
+		models.ActionCreate,
+		fmt.Sprintf("chat_message:%s", threadID),
+		c.ClientIP(),
+		c.Request.UserAgent(),
+		streamErr == "",
+		auditDetail,
+	)
+
+	if streamErr != "" {
+		return
+	}
+
+	if newTitle != "" {
+		if err := h.chatThreadService.UpdateThreadTitle(threadID, userIDStr, newTitle); err != nil {
+			log.Printf("Warning: Failed to update thread title: %v", err)
+		}
+	}
+
+	final := ChatResponse{
+		Response:  fullResponse.String(),
+		MessageID: assistantMessage.MessageID,
+		ThreadID:  threadID,
+		RunID:     runID,
+		NewTitle:  newTitle,
+		Data:      map[string]interface{}{"model_used": modelUsed},
+	}
+	if tokensUsed != nil {
+		final.Data["tokens_used"] = *tokensUsed
+	}
+	payload, err := json.Marshal(final)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(c.Writer, "event: done\ndata: %s\n\n", payload)
+	if canFlush {
+		flusher.Flush()
+	}
+}
+
+// buildAIServiceRequest assembles the payload processChatMessageWithAIService
+// and StreamChatMessage both send upstream, resolving user role/name and
+// recent thread history the same way for either transport.
+func (h *ChatHandler) buildAIServiceRequest(req ChatRequest, userID, threadID string) (AIServiceRequest, error) {
+	return resolveAIServiceRequest(h.userService, h.chatThreadService, req.Message, req.User.Role, req.User.Name, userID, threadID)
+}
+
+// resolveAIServiceRequest fills in whatever the caller didn't already know
+// about the user (role, name) from the database, attaches recent thread
+// history, and assembles the payload sent upstream. It's a free function
+// rather than a method so both ChatHandler and AIHandler can share it
+// without either depending on the other.
+func resolveAIServiceRequest(userService *services.UserService, chatThreadService *services.ChatThreadService, message, userRole, userName, userID, threadID string) (AIServiceRequest, error) {
+	if userRole == "" || userName == "" {
+		userIDUint, _ := strconv.ParseUint(userID, 10, 32)
+		var roleForQuery models.UserRole = models.RoleAdmin
+		if userRole != "" {
+			roleForQuery = models.UserRole(userRole)
+		}
+
+		user, err := userService.GetUser(uint(userIDUint), uint(userIDUint), roleForQuery)
+		if err == nil {
+			if userRole == "" {
+				userRole = string(user.Role)
+			}
+			if userName == "" {
+				userName = user.Name
+			}
+		} else {
+			log.Printf("Warning: Could not get user info from database: %v", err)
+			if userRole == "" {
+				userRole = "doctor"
+			}
+			if userName == "" {
+				userName = "Unknown User"
+			}
+		}
+	}
+
+	history, err := chatThreadService.GetThreadMessages(threadID, userID, 10)
+	if err != nil {
+		log.Printf("Warning: Could not get chat history: %v", err)
+	}
+
+	return AIServiceRequest{
+		Message:  message,
+		ThreadID: threadID,
+		UserID:   userID,
+		UserRole: userRole,
+		UserName: userName,
+		History:  history,
+	}, nil
+}
+
 // New endpoint methods
 func (h *ChatHandler) CreateThread(c *gin.Context) {
 	var req ThreadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request format",
-		})
+		c.Error(errs.New(errs.ErrValidationFailed, fmt.Sprintf("invalid request format: %v", err)))
 		return
 	}
 
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		c.Error(errs.New(errs.ErrUnauthenticated, "unauthorized"))
 		return
 	}
 
@@ -320,9 +492,7 @@ func (h *ChatHandler) CreateThread(c *gin.Context) {
 
 	thread, err := h.chatThreadService.CreateThread(fmt.Sprintf("%v", userID), title)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create thread",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -332,17 +502,13 @@ func (h *ChatHandler) CreateThread(c *gin.Context) {
 func (h *ChatHandler) GetThreads(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		c.Error(errs.New(errs.ErrUnauthenticated, "unauthorized"))
 		return
 	}
 
 	threads, err := h.chatThreadService.GetUserThreads(fmt.Sprintf("%v", userID), 50)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get threads",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -354,17 +520,13 @@ func (h *ChatHandler) GetThreads(c *gin.Context) {
 func (h *ChatHandler) GetThreadMessages(c *gin.Context) {
 	threadID := c.Param("thread_id")
 	if threadID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Thread ID required",
-		})
+		c.Error(errs.New(errs.ErrValidationFailed, "thread ID required"))
 		return
 	}
 
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		c.Error(errs.New(errs.ErrUnauthenticated, "unauthorized"))
 		return
 	}
 
@@ -373,9 +535,7 @@ func (h *ChatHandler) GetThreadMessages(c *gin.Context) {
 
 	messages, total, err := h.chatThreadService.GetThreadMessagesPage(threadID, fmt.Sprintf("%v", userID), page, pageSize)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Thread not found or access denied",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -387,32 +547,37 @@ func (h *ChatHandler) GetThreadMessages(c *gin.Context) {
 	})
 }
 
+// SubmitFeedback records thumbs-up/down feedback for a chat message. Mount
+// behind internal/middleware.IdempotencyMiddleware so a retried submission
+// replays the original response instead of silently overwriting the
+// feedback a moment later.
 func (h *ChatHandler) SubmitFeedback(c *gin.Context) {
 	var req FeedbackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request format",
-		})
+		c.Error(errs.New(errs.ErrValidationFailed, fmt.Sprintf("invalid request format: %v", err)))
 		return
 	}
 
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		c.Error(errs.New(errs.ErrUnauthenticated, "unauthorized"))
 		return
 	}
 
-	err := h.chatThreadService.SubmitFeedback(req.MessageID, fmt.Sprintf("%v", userID), req.Feedback)
+	message, err := h.chatThreadService.SubmitFeedback(req.MessageID, fmt.Sprintf("%v", userID), req.Feedback, req.Comment, req.Correction)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Message not found or access denied",
-		})
+		c.Error(err)
 		return
 	}
 
-	// Log feedback to LangSmith
+	// Best-effort: annotate the run in LangSmith itself, not just our DB.
+	// The feedback is already saved, so a failure here doesn't fail the request.
+	if message.RunID != "" {
+		if err := h.aiServiceClient.SubmitFeedback(message.RunID, req.Feedback, req.Comment, req.Correction); err != nil {
+			log.Printf("Failed to forward feedback to AI service for run %s: %v", message.RunID, err)
+		}
+	}
+
 	userIDUint, _ := strconv.ParseUint(fmt.Sprintf("%v", userID), 10, 32)
 	h.auditService.LogUserAction(
 		uint(userIDUint),
@@ -430,20 +595,73 @@ func (h *ChatHandler) SubmitFeedback(c *gin.Context) {
 	})
 }
 
+// RunFeedbackRequest is the body for SubmitRunFeedback: same shape as
+// FeedbackRequest but without a message_id, since the run ID comes from the
+// route instead.
+type RunFeedbackRequest struct {
+	Feedback   string `json:"feedback" binding:"required,oneof=thumbs_up thumbs_down"`
+	Comment    string `json:"comment"`
+	Correction string `json:"correction"`
+}
+
+// SubmitRunFeedback handles POST /runs/:run_id/feedback, recording feedback
+// against a RunID rather than a MessageID so dashboards that only track runs
+// (e.g. AIHandler.StreamChat's traces) can still collect it, and so per-run
+// thumbs-up rate over time can be computed the same way GetFeedbackStats
+// already does per-user.
+func (h *ChatHandler) SubmitRunFeedback(c *gin.Context) {
+	runID := c.Param("run_id")
+
+	var req RunFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.New(errs.ErrValidationFailed, fmt.Sprintf("invalid request format: %v", err)))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(errs.New(errs.ErrUnauthenticated, "unauthorized"))
+		return
+	}
+
+	message, err := h.chatThreadService.SubmitFeedbackByRun(runID, fmt.Sprintf("%v", userID), req.Feedback, req.Comment, req.Correction)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.aiServiceClient.SubmitFeedback(runID, req.Feedback, req.Comment, req.Correction); err != nil {
+		log.Printf("Failed to forward feedback to AI service for run %s: %v", runID, err)
+	}
+
+	userIDUint, _ := strconv.ParseUint(fmt.Sprintf("%v", userID), 10, 32)
+	h.auditService.LogUserAction(
+		uint(userIDUint),
+		models.ActionUpdate,
+		fmt.Sprintf("chat_run:%s", runID),
+		c.ClientIP(),
+		c.Request.UserAgent(),
+		true,
+		fmt.Sprintf("User submitted %s feedback for run %s", req.Feedback, runID),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Feedback submitted successfully",
+		"message_id": message.MessageID,
+	})
+}
+
 func (h *ChatHandler) GetFeedbackStats(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		c.Error(errs.New(errs.ErrUnauthenticated, "unauthorized"))
 		return
 	}
 
 	stats, err := h.chatThreadService.GetMessageFeedback(fmt.Sprintf("%v", userID), 30) // Last 30 days
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get feedback statistics",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -452,34 +670,69 @@ func (h *ChatHandler) GetFeedbackStats(c *gin.Context) {
 	})
 }
 
-func (h *ChatHandler) processSpecialCommands(message, role string, response *ChatResponse) error {
-	lowerMsg := strings.ToLower(message)
-
-	// Handle patient search queries
-	if strings.Contains(lowerMsg, "find patient") || strings.Contains(lowerMsg, "search patient") {
-		response.Actions = append(response.Actions, "patient_search")
-		response.Data["search_context"] = "patient_lookup"
+// ExportFeedback handles GET /chat/feedback/export?since=&format=jsonl,
+// streaming every thumbs-down message since the given time alongside its
+// preceding user turn and human correction, as newline-delimited JSON
+// suitable for fine-tuning or eval-set construction. The content returned
+// includes raw conversation text, so mount this route behind
+// auth.RequireRole(models.RoleAdmin).
+func (h *ChatHandler) ExportFeedback(c *gin.Context) {
+	format := c.DefaultQuery("format", "jsonl")
+	if format != "jsonl" {
+		c.Error(errs.New(errs.ErrValidationFailed, "unsupported format, only jsonl is supported"))
+		return
 	}
 
-	// Handle emergency access requests
-	if strings.Contains(lowerMsg, "emergency") && (role == "doctor" || role == "nurse") {
-		response.Actions = append(response.Actions, "emergency_access")
-		response.Data["emergency_context"] = "access_request"
+	since, err := parseRFC3339(c.Query("since"), time.Time{})
+	if err != nil {
+		c.Error(errs.New(errs.ErrValidationFailed, "invalid since timestamp"))
+		return
 	}
 
-	// Handle audit log requests
-	if strings.Contains(lowerMsg, "audit") || strings.Contains(lowerMsg, "logs") {
-		response.Actions = append(response.Actions, "audit_logs")
-		response.Data["audit_context"] = "log_access"
+	records, err := h.chatThreadService.ExportThumbsDownFeedback(since)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
-	// Handle patient creation requests
-	if strings.Contains(lowerMsg, "add patient") || strings.Contains(lowerMsg, "new patient") {
-		if role == "doctor" || role == "nurse" {
-			response.Actions = append(response.Actions, "create_patient")
-			response.Data["creation_context"] = "patient_form"
+	c.Writer.Header().Set("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			log.Printf("Failed to encode feedback export record: %v", err)
+			return
 		}
 	}
+}
+
+// processSpecialCommands dispatches message through the chat command
+// registry, merging whichever registered commands matched into response.
+// The command set itself lives in internal/chatcommands so new tools can be
+// registered (or disabled per tenant) without touching this handler.
+func (h *ChatHandler) processSpecialCommands(ctx context.Context, message, role string, userID uint, response *ChatResponse) error {
+	actions, data, err := h.commandRegistry.Dispatch(ctx, message, chatcommands.User{
+		ID:   userID,
+		Role: models.UserRole(role),
+	})
+	if err != nil {
+		return err
+	}
+
+	response.Actions = append(response.Actions, actions...)
+	for k, v := range data {
+		response.Data[k] = v
+	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// ListCommands returns every registered chat command and the roles allowed
+// to invoke it, for admin tooling and for clients that want to show a user
+// which tools are available to them.
+func (h *ChatHandler) ListCommands(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"commands": h.commandRegistry.List(),
+	})
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"healthsecure/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DeviceIdentityHandler exposes admin-only CRUD over device_identities, the
+// table internal/auth.MTLSMiddleware consults to map a trusted external
+// system's client certificate to a User account.
+type DeviceIdentityHandler struct {
+	db *gorm.DB
+}
+
+func NewDeviceIdentityHandler(db *gorm.DB) *DeviceIdentityHandler {
+	return &DeviceIdentityHandler{db: db}
+}
+
+// CreateDeviceIdentityRequest binds a new certificate to an existing user.
+type CreateDeviceIdentityRequest struct {
+	UserID          uint   `json:"user_id" binding:"required"`
+	CommonName      string `json:"common_name"`
+	SPIFFEID        string `json:"spiffe_id"`
+	CertFingerprint string `json:"cert_fingerprint" binding:"required"`
+}
+
+// CreateDeviceIdentity handles POST /admin/device-identities.
+func (h *DeviceIdentityHandler) CreateDeviceIdentity(c *gin.Context) {
+	var req CreateDeviceIdentityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	identity := &models.DeviceIdentity{
+		UserID:          req.UserID,
+		CommonName:      req.CommonName,
+		SPIFFEID:        req.SPIFFEID,
+		CertFingerprint: req.CertFingerprint,
+		CreatedAt:       time.Now(),
+	}
+
+	if err := h.db.Create(identity).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to bind device identity: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, identity)
+}
+
+// ListDeviceIdentities handles GET /admin/device-identities.
+func (h *DeviceIdentityHandler) ListDeviceIdentities(c *gin.Context) {
+	var identities []models.DeviceIdentity
+	if err := h.db.Order("created_at DESC").Find(&identities).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list device identities"})
+		return
+	}
+	c.JSON(http.StatusOK, identities)
+}
+
+// DeleteDeviceIdentity handles DELETE /admin/device-identities/:id and revokes
+// (rather than removes) the binding, so MTLSMiddleware stops accepting that
+// certificate while the audit trail of its having once been valid remains.
+func (h *DeviceIdentityHandler) DeleteDeviceIdentity(c *gin.Context) {
+	id := c.Param("id")
+
+	now := time.Now()
+	result := h.db.Model(&models.DeviceIdentity{}).Where("id = ?", id).Update("revoked_at", &now)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke device identity"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Device identity not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Device identity revoked"})
+}
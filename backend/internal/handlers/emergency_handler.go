@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"healthsecure/internal/errs"
+	"healthsecure/internal/models"
+	"healthsecure/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmergencyAccessHandler exposes the break-glass workflow backing the chat
+// "emergency" intent: request a grant, have it approved, list what's
+// currently active, and revoke early.
+type EmergencyAccessHandler struct {
+	emergencyAccessService *services.EmergencyAccessService
+}
+
+func NewEmergencyAccessHandler(emergencyAccessService *services.EmergencyAccessService) *EmergencyAccessHandler {
+	return &EmergencyAccessHandler{emergencyAccessService: emergencyAccessService}
+}
+
+type emergencyAccessRequest struct {
+	PatientID uint   `json:"patient_id" binding:"required"`
+	Reason    string `json:"reason" binding:"required"`
+}
+
+// RequestAccess handles POST /emergency/request, creating a pending grant
+// for the calling user against the named patient.
+func (h *EmergencyAccessHandler) RequestAccess(c *gin.Context) {
+	var req emergencyAccessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.New(errs.ErrValidationFailed, fmt.Sprintf("invalid request format: %v", err)))
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	grant, err := h.emergencyAccessService.RequestAccess(userID, req.PatientID, req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, grant)
+}
+
+// Approve handles POST /emergency/:id/approve. Only an admin can approve a
+// break-glass request, and never the clinician who filed it - otherwise the
+// two-step request/approve workflow is a no-op, since any doctor or nurse
+// could immediately approve their own request.
+func (h *EmergencyAccessHandler) Approve(c *gin.Context) {
+	id, ok := requireGrantID(c)
+	if !ok {
+		return
+	}
+
+	approverID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if models.UserRole(c.GetString("user_role")) != models.RoleAdmin {
+		c.Error(errs.New(errs.ErrNoPermission, "only an admin can approve emergency access requests"))
+		return
+	}
+
+	grant, err := h.emergencyAccessService.Approve(id, approverID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// ListActive handles GET /emergency/active, returning every grant currently
+// authorizing a break-glass read.
+func (h *EmergencyAccessHandler) ListActive(c *gin.Context) {
+	grants, err := h.emergencyAccessService.ActiveGrants()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"grants": grants})
+}
+
+// Revoke handles POST /emergency/:id/revoke, ending a grant's access
+// immediately regardless of its expiry.
+func (h *EmergencyAccessHandler) Revoke(c *gin.Context) {
+	id, ok := requireGrantID(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.emergencyAccessService.Revoke(id, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func requireUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(errs.New(errs.ErrUnauthenticated, "unauthorized"))
+		return 0, false
+	}
+
+	parsed, err := strconv.ParseUint(fmt.Sprintf("%v", userID), 10, 32)
+	if err != nil {
+		c.Error(errs.New(errs.ErrUnauthenticated, "unauthorized"))
+		return 0, false
+	}
+
+	return uint(parsed), true
+}
+
+func requireGrantID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errs.New(errs.ErrValidationFailed, "invalid grant id"))
+		return 0, false
+	}
+	return uint(id), true
+}
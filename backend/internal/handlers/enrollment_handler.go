@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+
+	"healthsecure/internal/enrollment"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EnrollmentHandler exposes the ACME-subset endpoints that let clinical
+// devices self-enroll for short-lived mTLS client certificates, mirroring
+// the newNonce/newAccount/newOrder/finalize/certificate flow from RFC 8555.
+type EnrollmentHandler struct {
+	provisioner *enrollment.Provisioner
+}
+
+func NewEnrollmentHandler(provisioner *enrollment.Provisioner) *EnrollmentHandler {
+	return &EnrollmentHandler{provisioner: provisioner}
+}
+
+// NewNonce handles HEAD/GET /acme/new-nonce.
+func (h *EnrollmentHandler) NewNonce(c *gin.Context) {
+	c.Header("Replay-Nonce", h.provisioner.NewNonce())
+	c.Status(http.StatusNoContent)
+}
+
+type newAccountRequest struct {
+	Nonce        string `json:"nonce" binding:"required"`
+	SerialNumber string `json:"serial_number" binding:"required"`
+	WardID       string `json:"ward_id" binding:"required"`
+}
+
+// NewAccount handles POST /acme/new-account.
+func (h *EnrollmentHandler) NewAccount(c *gin.Context) {
+	var req newAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "malformed"})
+		return
+	}
+
+	acc, err := h.provisioner.NewAccount(req.Nonce, req.SerialNumber, req.WardID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Replay-Nonce", h.provisioner.NewNonce())
+	c.JSON(http.StatusCreated, gin.H{"account_id": acc.ID})
+}
+
+type newOrderRequest struct {
+	Nonce         string   `json:"nonce" binding:"required"`
+	AccountID     string   `json:"account_id" binding:"required"`
+	Identifiers   []string `json:"identifiers" binding:"required"`
+	ChallengeType string   `json:"challenge_type" binding:"required"`
+}
+
+// NewOrder handles POST /acme/new-order.
+func (h *EnrollmentHandler) NewOrder(c *gin.Context) {
+	var req newOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "malformed"})
+		return
+	}
+
+	ord, err := h.provisioner.NewOrder(req.Nonce, req.AccountID, req.Identifiers, enrollment.ChallengeType(req.ChallengeType))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Replay-Nonce", h.provisioner.NewNonce())
+	c.JSON(http.StatusCreated, gin.H{
+		"order_id":       ord.ID,
+		"status":         ord.Status,
+		"challenge_type": ord.Challenge.Type,
+		"token":          ord.Challenge.Token,
+	})
+}
+
+type validateChallengeRequest struct {
+	OrderID string `json:"order_id" binding:"required"`
+	Token   string `json:"token" binding:"required"`
+}
+
+// ValidateChallenge handles POST /acme/challenge.
+func (h *EnrollmentHandler) ValidateChallenge(c *gin.Context) {
+	var req validateChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "malformed"})
+		return
+	}
+
+	if err := h.provisioner.ValidateChallenge(req.OrderID, req.Token); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
+
+type finalizeRequest struct {
+	OrderID string `json:"order_id" binding:"required"`
+	CSRPEM  string `json:"csr_pem" binding:"required"`
+}
+
+// Finalize handles POST /acme/finalize.
+func (h *EnrollmentHandler) Finalize(c *gin.Context) {
+	var req finalizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "malformed"})
+		return
+	}
+
+	ord, err := h.provisioner.Finalize(req.OrderID, []byte(req.CSRPEM))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"order_id": ord.ID, "status": ord.Status})
+}
+
+// Certificate handles GET /acme/certificate/:orderID.
+func (h *EnrollmentHandler) Certificate(c *gin.Context) {
+	certPEM, err := h.provisioner.Certificate(c.Param("orderID"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/pem-certificate-chain", certPEM)
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"healthsecure/configs"
+	"healthsecure/internal/auth"
+	"healthsecure/internal/models"
+	"healthsecure/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/gorm"
+)
+
+const webAuthnCeremonyCookie = "hs_webauthn_ceremony"
+const webAuthnCeremonyTTL = 5 * time.Minute
+
+// webAuthnCeremonyStore holds in-flight registration/login SessionData
+// between Begin* and Finish*, keyed by a random token handed to the caller
+// as an HttpOnly cookie. It is process-local; a clustered deployment should
+// back this with Redis the same way internal/middleware's rate limiter does.
+type webAuthnCeremonyStore struct {
+	mu        sync.Mutex
+	sessions  map[string]webauthn.SessionData
+	expiresAt map[string]time.Time
+}
+
+func newWebAuthnCeremonyStore() *webAuthnCeremonyStore {
+	return &webAuthnCeremonyStore{
+		sessions:  make(map[string]webauthn.SessionData),
+		expiresAt: make(map[string]time.Time),
+	}
+}
+
+func (s *webAuthnCeremonyStore) put(session webauthn.SessionData) (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(raw)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[token] = session
+	s.expiresAt[token] = time.Now().Add(webAuthnCeremonyTTL)
+	return token, nil
+}
+
+func (s *webAuthnCeremonyStore) take(token string) (webauthn.SessionData, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[token]
+	if !ok || time.Now().After(s.expiresAt[token]) {
+		delete(s.sessions, token)
+		delete(s.expiresAt, token)
+		return webauthn.SessionData{}, false
+	}
+
+	delete(s.sessions, token)
+	delete(s.expiresAt, token)
+	return session, true
+}
+
+// MFAHandler implements the TOTP and WebAuthn step-up enrollment/verification
+// endpoints gating sensitive PHI access per auth.RequireACR.
+type MFAHandler struct {
+	db          *gorm.DB
+	config      *configs.Config
+	userService *services.UserService
+	webAuthn    *auth.WebAuthnService
+	ceremonies  *webAuthnCeremonyStore
+}
+
+func NewMFAHandler(db *gorm.DB, config *configs.Config, userService *services.UserService, webAuthn *auth.WebAuthnService) *MFAHandler {
+	return &MFAHandler{
+		db:          db,
+		config:      config,
+		userService: userService,
+		webAuthn:    webAuthn,
+		ceremonies:  newWebAuthnCeremonyStore(),
+	}
+}
+
+// EnrollTOTP issues a fresh secret for the current user and stores it
+// unconfirmed until VerifyTOTP proves possession.
+func (h *MFAHandler) EnrollTOTP(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate TOTP secret"})
+		return
+	}
+
+	record := &models.UserTOTPSecret{UserID: userID, Secret: secret}
+	if err := h.db.Where("user_id = ?", userID).Assign(record).FirstOrCreate(record).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save TOTP secret"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret": secret,
+		"issuer": "HealthSecure",
+	})
+}
+
+type verifyTOTPRequest struct {
+	Code string `json:"code" binding:"required,len=6"`
+}
+
+// VerifyTOTP confirms enrollment (first call) or satisfies the step-up
+// challenge (subsequent calls), returning a short-lived step-up token that
+// carries the amr/acr claims RequireACR checks for.
+func (h *MFAHandler) VerifyTOTP(c *gin.Context) {
+	var req verifyTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	userRole := models.UserRole(c.GetString("user_role"))
+
+	var secretRecord models.UserTOTPSecret
+	if err := h.db.Where("user_id = ?", userID).First(&secretRecord).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "TOTP is not enrolled for this account"})
+		return
+	}
+
+	if !auth.VerifyTOTPCode(secretRecord.Secret, req.Code) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid TOTP code"})
+		return
+	}
+
+	if !secretRecord.Confirmed {
+		h.db.Model(&secretRecord).Updates(map[string]interface{}{"confirmed": true})
+	}
+
+	user, err := h.userService.GetUser(userID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return
+	}
+
+	stepUpToken, err := auth.GenerateStepUpToken(h.config, user, []string{auth.AMRPassword, auth.AMRTOTP})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue step-up token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"step_up_token": stepUpToken})
+}
+
+// BeginWebAuthnRegistration starts a credential-creation ceremony and stashes
+// the SessionData server-side, handing the caller an HttpOnly cookie to
+// present back to FinishWebAuthnRegistration.
+func (h *MFAHandler) BeginWebAuthnRegistration(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	userRole := models.UserRole(c.GetString("user_role"))
+
+	user, err := h.userService.GetUser(userID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return
+	}
+
+	session, options, err := h.webAuthn.BeginRegistration(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.ceremonies.put(*session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start ceremony"})
+		return
+	}
+
+	c.SetCookie(webAuthnCeremonyCookie, token, int(webAuthnCeremonyTTL.Seconds()), "/", "", true, true)
+	c.JSON(http.StatusOK, options)
+}
+
+// FinishWebAuthnRegistration verifies the attestation and persists the
+// resulting credential.
+func (h *MFAHandler) FinishWebAuthnRegistration(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	userRole := models.UserRole(c.GetString("user_role"))
+
+	session, ok := h.sessionFromCookie(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No pending WebAuthn ceremony"})
+		return
+	}
+
+	user, err := h.userService.GetUser(userID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return
+	}
+
+	if err := h.webAuthn.FinishRegistration(user, session, c.Request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "WebAuthn credential registered"})
+}
+
+// BeginWebAuthnLogin starts an assertion ceremony for the step-up challenge.
+func (h *MFAHandler) BeginWebAuthnLogin(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	userRole := models.UserRole(c.GetString("user_role"))
+
+	user, err := h.userService.GetUser(userID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return
+	}
+
+	session, options, err := h.webAuthn.BeginLogin(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.ceremonies.put(*session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start ceremony"})
+		return
+	}
+
+	c.SetCookie(webAuthnCeremonyCookie, token, int(webAuthnCeremonyTTL.Seconds()), "/", "", true, true)
+	c.JSON(http.StatusOK, options)
+}
+
+// FinishWebAuthnLogin verifies the assertion and, on success, issues a
+// step-up token with amr=["pwd","webauthn"].
+func (h *MFAHandler) FinishWebAuthnLogin(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	userRole := models.UserRole(c.GetString("user_role"))
+
+	session, ok := h.sessionFromCookie(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No pending WebAuthn ceremony"})
+		return
+	}
+
+	user, err := h.userService.GetUser(userID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return
+	}
+
+	if err := h.webAuthn.FinishLogin(user, session, c.Request); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	stepUpToken, err := auth.GenerateStepUpToken(h.config, user, []string{auth.AMRPassword, auth.AMRWebAuthn})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue step-up token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"step_up_token": stepUpToken})
+}
+
+func (h *MFAHandler) sessionFromCookie(c *gin.Context) (webauthn.SessionData, bool) {
+	token, err := c.Cookie(webAuthnCeremonyCookie)
+	if err != nil || token == "" {
+		return webauthn.SessionData{}, false
+	}
+	return h.ceremonies.take(token)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+
+	"healthsecure/internal/auth"
+	"healthsecure/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OAuth2Handler exposes the authorization server endpoints that let external
+// EHR clients, mobile apps, and lab systems obtain tokens against HealthSecure.
+type OAuth2Handler struct {
+	server *auth.OAuth2Server
+	db     *gorm.DB
+}
+
+func NewOAuth2Handler(server *auth.OAuth2Server, db *gorm.DB) *OAuth2Handler {
+	return &OAuth2Handler{server: server, db: db}
+}
+
+// Authorize handles GET /oauth2/authorize for the authorization_code grant.
+// It assumes the caller has already been authenticated as a HealthSecure
+// user via AuthMiddleware (the consent/login UI happens on the front end).
+func (h *OAuth2Handler) Authorize(c *gin.Context) {
+	clientID := c.Query("client_id")
+	redirectURI := c.Query("redirect_uri")
+	scope := c.Query("scope")
+	state := c.Query("state")
+	codeChallenge := c.Query("code_challenge")
+	codeChallengeMethod := c.Query("code_challenge_method")
+
+	if c.Query("response_type") != "code" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_response_type"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "login_required"})
+		return
+	}
+
+	var client models.OAuthClient
+	if err := h.db.Where("client_id = ? AND active = ?", clientID, true).First(&client).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_client"})
+		return
+	}
+
+	code, err := h.server.GenerateAuthorizationCode(&client, userID, redirectURI, scope, codeChallenge, codeChallengeMethod)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":  code,
+		"state": state,
+	})
+}
+
+type tokenRequest struct {
+	GrantType    string `form:"grant_type" binding:"required"`
+	ClientID     string `form:"client_id" binding:"required"`
+	ClientSecret string `form:"client_secret" binding:"required"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+}
+
+// Token handles POST /oauth2/token for the authorization_code, refresh_token,
+// and client_credentials grant types.
+func (h *OAuth2Handler) Token(c *gin.Context) {
+	var req tokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+
+	var (
+		tokens *auth.OAuth2Tokens
+		err    error
+	)
+
+	switch models.OAuthGrantType(req.GrantType) {
+	case models.GrantAuthorizationCode:
+		tokens, err = h.server.ExchangeAuthorizationCode(req.ClientID, req.ClientSecret, req.Code, req.RedirectURI, req.CodeVerifier)
+	case models.GrantRefreshToken:
+		tokens, err = h.server.RefreshGrant(req.ClientID, req.ClientSecret, req.RefreshToken)
+	case models.GrantClientCredentials:
+		tokens, err = h.server.ClientCredentialsGrant(req.ClientID, req.ClientSecret, req.Scope)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant", "error_description": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+type introspectRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// Introspect handles POST /oauth2/introspect (RFC 7662).
+func (h *OAuth2Handler) Introspect(c *gin.Context) {
+	var req introspectRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+
+	result, err := h.server.IntrospectToken(req.Token)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"active": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A ceremony opened by BeginLogin must never be redeemable at
+// FinishRegistration (and vice versa) - see FinishRegistration/FinishLogin's
+// ceremony.kind check in auth.go. Without it, an attacker who can start a
+// login ceremony for a victim (BeginLogin only requires knowing the
+// victim's email) could redeem it as a registration ceremony and enroll
+// their own credential on the victim's account.
+func TestWebAuthnSignupCeremonyStore_TagsCeremonyKind(t *testing.T) {
+	store := newWebAuthnSignupCeremonyStore()
+
+	token, err := store.put(webauthn.SessionData{}, 42, webAuthnCeremonyLogin)
+	require.NoError(t, err)
+
+	ceremony, ok := store.take(token)
+	require.True(t, ok)
+	assert.Equal(t, webAuthnCeremonyLogin, ceremony.kind)
+	assert.Equal(t, uint(42), ceremony.userID)
+}
+
+func TestWebAuthnSignupCeremonyStore_TakeIsSingleUse(t *testing.T) {
+	store := newWebAuthnSignupCeremonyStore()
+
+	token, err := store.put(webauthn.SessionData{}, 1, webAuthnCeremonyRegistration)
+	require.NoError(t, err)
+
+	_, ok := store.take(token)
+	require.True(t, ok)
+
+	_, ok = store.take(token)
+	assert.False(t, ok, "a ceremony token must not be redeemable twice")
+}
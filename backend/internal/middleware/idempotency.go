@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyRecordTTL is how long a stored response is replayed for before
+// the same Idempotency-Key is treated as free to reuse.
+const idempotencyRecordTTL = 24 * time.Hour
+
+// IdempotencyMiddleware makes a mutating endpoint safe to retry: when the
+// caller sends an Idempotency-Key header, the first successful (2xx) response
+// is cached under (key, hash of method+path+user+body) and replayed verbatim
+// for any later request with the same key and hash. A request reusing the
+// key with a different hash - a different body, path, or caller - gets 409
+// rather than either the wrong cached response or a second side effect.
+// Requests with no Idempotency-Key header pass through unchanged.
+func IdempotencyMiddleware(store IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := idempotencyHash(c, body)
+
+		existing, err := store.Load(key)
+		if err != nil {
+			// Fail open: a store outage shouldn't block a retriable request.
+			c.Next()
+			return
+		}
+
+		if existing != nil {
+			if existing.Hash != hash {
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+					"error": "Idempotency-Key was already used with a different request",
+				})
+				return
+			}
+
+			if existing.StatusCode == 0 {
+				// Another request with this key reserved it and hasn't
+				// saved a response yet - it's still in flight, not merely
+				// cached, so this request must not run the handler too.
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+					"error": "a request with this Idempotency-Key is already being processed",
+				})
+				return
+			}
+
+			c.Header("Idempotency-Replayed", "true")
+			c.Data(existing.StatusCode, existing.ContentType, existing.Body)
+			c.Abort()
+			return
+		}
+
+		reserved, err := store.Reserve(key, hash, idempotencyRecordTTL)
+		if err != nil {
+			// Fail open: a store outage shouldn't block a retriable request.
+			c.Next()
+			return
+		}
+		if !reserved {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+				"error": "a request with this Idempotency-Key is already being processed",
+			})
+			return
+		}
+
+		buffered := &bufferingResponseWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = buffered
+		c.Next()
+
+		if buffered.Status() >= 200 && buffered.Status() < 300 {
+			_ = store.Save(key, &IdempotencyRecord{
+				Hash:        hash,
+				StatusCode:  buffered.Status(),
+				ContentType: buffered.Header().Get("Content-Type"),
+				Body:        buffered.body.Bytes(),
+				ExpiresAt:   time.Now().Add(idempotencyRecordTTL),
+			})
+		} else {
+			// Don't let a failed attempt squat on the key for the full TTL -
+			// the caller is expected to retry a failed mutation.
+			_ = store.Release(key)
+		}
+	}
+}
+
+// idempotencyHash fingerprints a request the same way so two requests are
+// only ever considered "the same" if method, path, caller, and body all
+// match.
+func idempotencyHash(c *gin.Context, body []byte) string {
+	userID, _ := c.Get("user_id")
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%v\n", c.Request.Method, c.Request.URL.Path, userID)
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bufferingResponseWriter tees everything written through it into an
+// in-memory buffer (for IdempotencyMiddleware to cache afterwards) while
+// still writing through to the real client normally.
+type bufferingResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bufferingResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bufferingResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
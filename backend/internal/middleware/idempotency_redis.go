@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisIdempotencyStore is an IdempotencyStore backed by Redis, so a
+// replayed response survives a restart and is visible across every instance
+// behind a load balancer, unlike MemoryIdempotencyStore.
+type RedisIdempotencyStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func NewRedisIdempotencyStore(client redis.UniversalClient) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{client: client, prefix: "idempotency:"}
+}
+
+func (s *RedisIdempotencyStore) Load(key string) (*IdempotencyRecord, error) {
+	raw, err := s.client.Get(context.Background(), s.prefix+key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load idempotency record: %w", err)
+	}
+
+	var record IdempotencyRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode idempotency record: %w", err)
+	}
+	return &record, nil
+}
+
+func (s *RedisIdempotencyStore) Save(key string, record *IdempotencyRecord) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency record: %w", err)
+	}
+
+	ttl := time.Until(record.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	if err := s.client.Set(context.Background(), s.prefix+key, raw, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save idempotency record: %w", err)
+	}
+	return nil
+}
+
+// Reserve claims key via SETNX: Redis only lets one caller's SET succeed
+// when the key doesn't already exist, so concurrent requests sharing a key
+// race for the same atomic operation instead of both passing a separate
+// Load check before either Saves.
+func (s *RedisIdempotencyStore) Reserve(key, hash string, ttl time.Duration) (bool, error) {
+	raw, err := json.Marshal(&IdempotencyRecord{Hash: hash, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode idempotency reservation: %w", err)
+	}
+
+	ok, err := s.client.SetNX(context.Background(), s.prefix+key, raw, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	return ok, nil
+}
+
+func (s *RedisIdempotencyStore) Release(key string) error {
+	if err := s.client.Del(context.Background(), s.prefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency reservation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+// IdempotencyRecord is the stored outcome of one mutating request, keyed by
+// the client's Idempotency-Key. Hash guards against the same key being
+// reused for a materially different request (IdempotencyMiddleware returns
+// 409 rather than silently replaying the wrong response in that case).
+type IdempotencyRecord struct {
+	Hash        string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// IdempotencyStore persists one IdempotencyRecord per Idempotency-Key, so
+// IdempotencyMiddleware can be backed by either an in-process
+// MemoryIdempotencyStore or a cluster-wide RedisIdempotencyStore without the
+// middleware itself changing.
+type IdempotencyStore interface {
+	// Load returns the record for key, or nil if none exists or it has
+	// expired.
+	Load(key string) (*IdempotencyRecord, error)
+	// Save stores record under key until record.ExpiresAt.
+	Save(key string, record *IdempotencyRecord) error
+	// Reserve atomically claims key for hash if no live record (reserved or
+	// saved) already exists for it, returning true if this caller won the
+	// claim. A concurrent caller that loses the race gets false and must not
+	// run the handler - without this, Load-then-run-then-Save leaves a
+	// window where two requests sharing a key both pass Load before either
+	// Save, and both execute the handler.
+	Reserve(key, hash string, ttl time.Duration) (bool, error)
+	// Release removes a reservation that didn't end in a saved response
+	// (e.g. the handler errored), so the key is free to retry instead of
+	// being stuck rejecting every request until ttl expires.
+	Release(key string) error
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore. Unlike the map it could
+// otherwise be, it evicts expired records on a background sweep so memory
+// doesn't grow without bound over a long-running process; use
+// RedisIdempotencyStore instead when keys need to survive a restart or be
+// shared across instances.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*IdempotencyRecord
+}
+
+// NewMemoryIdempotencyStore creates a MemoryIdempotencyStore and starts a
+// background janitor that evicts expired records every sweepInterval.
+func NewMemoryIdempotencyStore(sweepInterval time.Duration) *MemoryIdempotencyStore {
+	s := &MemoryIdempotencyStore{records: make(map[string]*IdempotencyRecord)}
+	go s.sweep(sweepInterval)
+	return s
+}
+
+func (s *MemoryIdempotencyStore) Load(key string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok || time.Now().After(record.ExpiresAt) {
+		return nil, nil
+	}
+	return record, nil
+}
+
+func (s *MemoryIdempotencyStore) Save(key string, record *IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = record
+	return nil
+}
+
+// Reserve claims key by inserting a placeholder record (StatusCode 0, which
+// Save never stores for a real response) - IdempotencyMiddleware treats that
+// sentinel as "a request with this key is already in flight" on Load. The
+// check-and-insert happens under the same lock as Load/Save, which is what
+// makes two concurrent callers racing for the same key safe: exactly one of
+// them observes no live record and gets to insert it.
+func (s *MemoryIdempotencyStore) Reserve(key, hash string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[key]; ok && time.Now().Before(existing.ExpiresAt) {
+		return false, nil
+	}
+
+	s.records[key] = &IdempotencyRecord{Hash: hash, ExpiresAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryIdempotencyStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		s.mu.Lock()
+		for key, record := range s.records {
+			if now.After(record.ExpiresAt) {
+				delete(s.records, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryIdempotencyStore_ReserveOnlyLetsOneCallerClaimAKey(t *testing.T) {
+	store := NewMemoryIdempotencyStore(time.Minute)
+
+	var wins int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := store.Reserve("key", "hash", time.Minute)
+			require.NoError(t, err)
+			if ok {
+				atomic.AddInt32(&wins, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), wins)
+}
+
+func TestMemoryIdempotencyStore_ReleaseFreesKeyForRetry(t *testing.T) {
+	store := NewMemoryIdempotencyStore(time.Minute)
+
+	ok, err := store.Reserve("key", "hash", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, store.Release("key"))
+
+	ok, err = store.Reserve("key", "hash", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestIdempotencyMiddleware_ConcurrentRequestsOnlyRunHandlerOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := NewMemoryIdempotencyStore(time.Minute)
+
+	var executions int32
+	router := gin.New()
+	router.Use(IdempotencyMiddleware(store))
+	router.POST("/refresh", func(c *gin.Context) {
+		atomic.AddInt32(&executions, 1)
+		time.Sleep(20 * time.Millisecond) // widen the race window
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	const concurrency = 10
+	codes := make([]int, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/refresh", nil)
+			req.Header.Set("Idempotency-Key", "shared-key")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			codes[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&executions), "handler must run exactly once for a shared Idempotency-Key")
+
+	var ok, conflict int
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			ok++
+		case http.StatusConflict:
+			conflict++
+		default:
+			t.Fatalf("unexpected status code %d", code)
+		}
+	}
+	assert.Equal(t, 1, ok)
+	assert.Equal(t, concurrency-1, conflict)
+}
+
+func TestIdempotencyMiddleware_FailedAttemptReleasesKeyForRetry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := NewMemoryIdempotencyStore(time.Minute)
+
+	attempt := 0
+	router := gin.New()
+	router.Use(IdempotencyMiddleware(store))
+	router.POST("/refresh", func(c *gin.Context) {
+		attempt++
+		if attempt == 1 {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/refresh", nil)
+	req.Header.Set("Idempotency-Key", "retry-key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/refresh", nil)
+	req.Header.Set("Idempotency-Key", "retry-key")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, attempt)
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript implements the sliding-window-log algorithm
+// atomically: drop entries older than now-window, count what's left, and -
+// if under limit - record this request and refresh the key's TTL. Doing the
+// count-then-record decision inside the script (rather than ZCARD then ZADD
+// as two round trips from Go) is what keeps it race-free under concurrent
+// callers sharing the same key across instances.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local member = ARGV[4]
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+
+if count < limit then
+    redis.call('ZADD', key, now, member)
+    redis.call('PEXPIRE', key, window)
+    return {1, limit - count - 1, 0}
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local retry_after = 0
+if oldest[2] ~= nil then
+    retry_after = tonumber(oldest[2]) + window - now
+end
+return {0, 0, retry_after}
+`)
+
+// RedisStore is a RateLimitStore backed by Redis sorted sets, so a limit
+// holds across every instance behind a load balancer instead of resetting
+// per-process the way MemoryStore does.
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Allow(key string, limit int, per time.Duration) (allowed bool, retryAfter time.Duration, remaining int, err error) {
+	now := time.Now().UnixMilli()
+	window := per.Milliseconds()
+
+	member, err := uniqueMember(now)
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("failed to generate rate limit entry: %w", err)
+	}
+
+	res, err := slidingWindowScript.Run(context.Background(), s.client, []string{key}, now, window, limit, member).Result()
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("rate limit script failed: %w", err)
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected rate limit script result: %v", res)
+	}
+
+	allowedInt, _ := values[0].(int64)
+	remainingInt, _ := values[1].(int64)
+	retryAfterMs, _ := values[2].(int64)
+
+	return allowedInt == 1, time.Duration(retryAfterMs) * time.Millisecond, int(remainingInt), nil
+}
+
+// uniqueMember gives each request its own sorted-set member even when two
+// requests land in the same millisecond, so ZADD never collapses them.
+func uniqueMember(now int64) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%s", now, hex.EncodeToString(suffix)), nil
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitStore decides whether one more request for key is allowed within
+// a limit-per-per sliding window, so RateLimiter can be backed by either an
+// in-process MemoryStore or a cluster-wide RedisStore without the
+// middleware itself changing.
+type RateLimitStore interface {
+	// Allow records a request for key if it fits under limit within the
+	// last per, and reports whether it was allowed, how long to wait before
+	// retrying if not, and how many requests remain in the current window
+	// if so.
+	Allow(key string, limit int, per time.Duration) (allowed bool, retryAfter time.Duration, remaining int, err error)
+}
+
+// memoryBucket is one key's sliding window of request timestamps.
+type memoryBucket struct {
+	timestamps []time.Time
+	lastSeen   time.Time
+}
+
+// MemoryStore is an in-process sliding-window-log RateLimitStore. Unlike the
+// map it replaces, it evicts buckets that have gone idle so memory doesn't
+// grow without bound over a long-running process; use RedisStore instead
+// when limits need to hold across more than one instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+}
+
+// NewMemoryStore creates a MemoryStore and starts a background janitor that,
+// every sweepInterval, evicts buckets that have seen no request for idleTTL.
+func NewMemoryStore(sweepInterval, idleTTL time.Duration) *MemoryStore {
+	s := &MemoryStore{buckets: make(map[string]*memoryBucket)}
+	go s.sweep(sweepInterval, idleTTL)
+	return s
+}
+
+func (s *MemoryStore) Allow(key string, limit int, per time.Duration) (bool, time.Duration, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{}
+		s.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	cutoff := now.Add(-per)
+	fresh := b.timestamps[:0]
+	for _, ts := range b.timestamps {
+		if ts.After(cutoff) {
+			fresh = append(fresh, ts)
+		}
+	}
+	b.timestamps = fresh
+
+	if len(b.timestamps) >= limit {
+		retryAfter := b.timestamps[0].Add(per).Sub(now)
+		if retryAfter < 0 {
+			retryAfter = 0
+		}
+		return false, retryAfter, 0, nil
+	}
+
+	b.timestamps = append(b.timestamps, now)
+	return true, 0, limit - len(b.timestamps), nil
+}
+
+func (s *MemoryStore) sweep(interval, idleTTL time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.mu.Lock()
+		cutoff := time.Now().Add(-idleTTL)
+		for key, b := range s.buckets {
+			if b.lastSeen.Before(cutoff) {
+				delete(s.buckets, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
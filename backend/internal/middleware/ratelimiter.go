@@ -1,46 +1,113 @@
 package middleware
 
 import (
+	"fmt"
+	"log"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-var (
-	requests = make(map[string][]int64)
-	mu       sync.Mutex
-)
+// KeyFunc derives a rate-limit key from the request: by client IP, by
+// authenticated user, or by a caller-supplied token.
+type KeyFunc func(c *gin.Context) string
+
+// IPKey rate-limits by client IP, the default for anonymous endpoints.
+func IPKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// UserIDKey rate-limits by the authenticated user set in context under
+// "user_id", falling back to IPKey for requests that have none.
+func UserIDKey(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return IPKey(c)
+}
+
+// AITokenKey rate-limits the AI endpoint by its X-AI-Token header rather
+// than IP, so one caller's traffic can't starve another's through a shared
+// gateway IP; it falls back to IPKey if the header is absent.
+func AITokenKey(c *gin.Context) string {
+	if token := c.GetHeader("X-AI-Token"); token != "" {
+		return "ai-token:" + token
+	}
+	return IPKey(c)
+}
+
+// RateLimitRule is one route's (or the default) limit: how many requests
+// per window, and how to derive the key that window is tracked under.
+type RateLimitRule struct {
+	Limit   int
+	Per     time.Duration
+	KeyFunc KeyFunc
+}
+
+// RateLimitConfig maps a route name to its RateLimitRule, falling back to
+// Default for anything not listed. Route names are caller-chosen labels
+// (e.g. "login"), not literal paths, so the same rule can cover an endpoint
+// mounted under more than one path.
+type RateLimitConfig struct {
+	Default RateLimitRule
+	Routes  map[string]RateLimitRule
+}
+
+// DefaultRateLimitConfig is the stock per-route tuning this service ships
+// with: tight windows on the brute-force-prone auth endpoints, and the AI
+// endpoint keyed by its own token instead of IP.
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Default: RateLimitRule{Limit: 100, Per: time.Minute, KeyFunc: IPKey},
+		Routes: map[string]RateLimitRule{
+			"login":          {Limit: 5, Per: time.Minute, KeyFunc: IPKey},
+			"signup":         {Limit: 3, Per: time.Minute, KeyFunc: IPKey},
+			"oauth_callback": {Limit: 10, Per: time.Minute, KeyFunc: IPKey},
+			"ai":             {Limit: 30, Per: time.Minute, KeyFunc: AITokenKey},
+		},
+	}
+}
+
+// RateLimiter returns a gin middleware enforcing config.Routes[route]
+// (or config.Default if route isn't listed), backed by store. store is
+// typically a single shared *MemoryStore or *RedisStore reused across every
+// route this is mounted on, so the sweep goroutine/Redis connection is
+// shared too.
+func RateLimiter(store RateLimitStore, config RateLimitConfig, route string) gin.HandlerFunc {
+	rule, ok := config.Routes[route]
+	if !ok {
+		rule = config.Default
+	}
+
+	keyFunc := rule.KeyFunc
+	if keyFunc == nil {
+		keyFunc = IPKey
+	}
 
-func RateLimiter(limit int, per time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		mu.Lock()
-		defer mu.Unlock()
+		key := fmt.Sprintf("%s:%s:%s", route, c.FullPath(), keyFunc(c))
 
-		now := time.Now().UnixNano()
-		if _, found := requests[ip]; !found {
-			requests[ip] = []int64{now}
+		allowed, retryAfter, remaining, err := store.Allow(key, rule.Limit, rule.Per)
+		if err != nil {
+			// Fail open: a rate limit store outage shouldn't take the whole
+			// API down with it.
+			log.Printf("rate limit store error for %s: %v", key, err)
 			c.Next()
 			return
 		}
 
-		// Remove old timestamps
-		var newTimestamps []int64
-		for _, ts := range requests[ip] {
-			if now-ts < per.Nanoseconds() {
-				newTimestamps = append(newTimestamps, ts)
-			}
-		}
-		requests[ip] = newTimestamps
+		c.Header("X-RateLimit-Limit", strconv.Itoa(rule.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(retryAfter).Unix(), 10))
 
-		if len(requests[ip]) >= limit {
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
 			return
 		}
 
-		requests[ip] = append(requests[ip], now)
 		c.Next()
 	}
 }
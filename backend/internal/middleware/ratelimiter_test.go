@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_AllowsUpToLimitThenDenies(t *testing.T) {
+	store := NewMemoryStore(time.Minute, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		allowed, _, remaining, err := store.Allow("k", 3, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, 3-(i+1), remaining)
+	}
+
+	allowed, retryAfter, _, err := store.Allow("k", 3, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Greater(t, retryAfter, time.Duration(0))
+}
+
+func TestMemoryStore_WindowExpiryAllowsAgain(t *testing.T) {
+	store := NewMemoryStore(time.Minute, time.Minute)
+
+	allowed, _, _, err := store.Allow("k", 1, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	allowed, _, _, err = store.Allow("k", 1, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	time.Sleep(20 * time.Millisecond)
+
+	allowed, _, _, err = store.Allow("k", 1, 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestMemoryStore_IndependentKeysDoNotShareBuckets(t *testing.T) {
+	store := NewMemoryStore(time.Minute, time.Minute)
+
+	allowed, _, _, err := store.Allow("a", 1, time.Minute)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	allowed, _, _, err = store.Allow("b", 1, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestRateLimiter_AbortsWithTooManyRequestsOnceOverLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := NewMemoryStore(time.Minute, time.Minute)
+	config := RateLimitConfig{Default: RateLimitRule{Limit: 1, Per: time.Minute, KeyFunc: IPKey}}
+
+	router := gin.New()
+	router.Use(RateLimiter(store, config, "default"))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	req = httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestRateLimiter_UnknownRouteFallsBackToDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := NewMemoryStore(time.Minute, time.Minute)
+	config := RateLimitConfig{
+		Default: RateLimitRule{Limit: 2, Per: time.Minute, KeyFunc: IPKey},
+		Routes:  map[string]RateLimitRule{"login": {Limit: 1, Per: time.Minute, KeyFunc: IPKey}},
+	}
+
+	router := gin.New()
+	router.Use(RateLimiter(store, config, "not_configured"))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+}
@@ -19,15 +19,17 @@ func (ct *ChatThread) TableName() string {
 }
 
 type ChatMessage struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	ThreadID   string    `json:"thread_id" gorm:"not null;index"`
-	MessageID  string    `json:"message_id" gorm:"uniqueIndex;not null;size:255"`
-	Role       string    `json:"role" gorm:"not null"` // "user" or "assistant"
-	Content    string    `json:"content" gorm:"type:text"`
-	RunID      string    `json:"run_id"` // LangSmith run ID
-	CreatedAt  time.Time `json:"created_at"`
-	Feedback   *string   `json:"feedback"` // "thumbs_up", "thumbs_down", or null
-	FeedbackAt *time.Time `json:"feedback_at"`
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	ThreadID           string     `json:"thread_id" gorm:"not null;index"`
+	MessageID          string     `json:"message_id" gorm:"uniqueIndex;not null;size:255"`
+	Role               string     `json:"role" gorm:"not null"` // "user" or "assistant"
+	Content            string     `json:"content" gorm:"type:text"`
+	RunID              string     `json:"run_id"` // LangSmith run ID
+	CreatedAt          time.Time  `json:"created_at"`
+	Feedback           *string    `json:"feedback"` // "thumbs_up", "thumbs_down", or null
+	FeedbackAt         *time.Time `json:"feedback_at"`
+	FeedbackComment    *string    `json:"feedback_comment" gorm:"type:text"`
+	FeedbackCorrection *string    `json:"feedback_correction" gorm:"type:text"`
 }
 
 func (cm *ChatMessage) TableName() string {
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ChatRun is one traced assistant turn: a LangSmith-style run with its own
+// RunID, an optional ParentRunID when it was spawned by another run (e.g. a
+// tool call), and the inputs/outputs/status a tracing dashboard needs to
+// reconstruct what happened without replaying the conversation.
+type ChatRun struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	RunID       string     `json:"run_id" gorm:"uniqueIndex;not null;size:36"`
+	ParentRunID *string    `json:"parent_run_id" gorm:"size:36;index"`
+	ThreadID    string     `json:"thread_id" gorm:"not null;index"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     *time.Time `json:"end_time"`
+	Inputs      string     `json:"inputs" gorm:"type:text"`
+	Outputs     string     `json:"outputs" gorm:"type:text"`
+	Status      string     `json:"status" gorm:"size:20"` // "running", "completed", or "error"
+	Tags        string     `json:"tags" gorm:"type:text"` // comma-separated
+}
+
+func (cr *ChatRun) TableName() string {
+	return "chat_runs"
+}
+
+// TagList splits Tags back into the slice callers built it from.
+func (cr *ChatRun) TagList() []string {
+	if cr.Tags == "" {
+		return nil
+	}
+	return strings.Split(cr.Tags, ",")
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Device is the first-class identity for a bedside tablet, lab instrument,
+// or other clinical endpoint that authenticates via a short-lived mTLS
+// client certificate issued by internal/enrollment instead of as a human
+// User. UserID links the device to its RoleDevice User row (one per device)
+// so existing role-based middleware keeps working unchanged.
+type Device struct {
+	ID            uint       `json:"id" gorm:"primaryKey;type:bigint unsigned;autoIncrement"`
+	UserID        uint       `json:"user_id" gorm:"not null;uniqueIndex;type:bigint unsigned"`
+	SerialNumber  string     `json:"serial_number" gorm:"type:varchar(128);unique;not null;index"`
+	WardID        string     `json:"ward_id" gorm:"type:varchar(64);index"`
+	AllowedScopes string     `json:"allowed_scopes" gorm:"type:text"` // space-separated
+	NotAfter      time.Time  `json:"not_after" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+func (d *Device) TableName() string {
+	return "devices"
+}
+
+func (d *Device) IsExpired() bool {
+	return time.Now().After(d.NotAfter)
+}
+
+func (d *Device) IsRevoked() bool {
+	return d.RevokedAt != nil
+}
+
+func (d *Device) Scopes() []string {
+	return strings.Fields(d.AllowedScopes)
+}
+
+func (d *Device) HasScope(scope string) bool {
+	for _, s := range d.Scopes() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
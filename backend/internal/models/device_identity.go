@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// DeviceIdentity binds a trusted external system's mTLS client certificate
+// (an EHR gateway, a lab analyzer) to a User account, the same way Device
+// does for bedside tablets enrolled via internal/enrollment - but admin
+// managed via POST /admin/device-identities rather than self-provisioned,
+// and matched by the certificate's Subject CN or SPIFFE URI SAN rather than
+// a serial number.
+type DeviceIdentity struct {
+	ID              uint       `json:"id" gorm:"primaryKey;type:bigint unsigned;autoIncrement"`
+	UserID          uint       `json:"user_id" gorm:"not null;index;type:bigint unsigned"`
+	CommonName      string     `json:"common_name" gorm:"type:varchar(191);index"`
+	SPIFFEID        string     `json:"spiffe_id" gorm:"type:varchar(191);index"`
+	CertFingerprint string     `json:"cert_fingerprint" gorm:"type:varchar(64);unique;not null"`
+	CreatedAt       time.Time  `json:"created_at"`
+	RevokedAt       *time.Time `json:"revoked_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+func (d *DeviceIdentity) TableName() string {
+	return "device_identities"
+}
+
+func (d *DeviceIdentity) IsRevoked() bool {
+	return d.RevokedAt != nil
+}
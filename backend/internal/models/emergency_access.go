@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EmergencyAccessGrant is a break-glass grant letting a doctor or nurse
+// bypass the normal MedicalRecord.CanBeAccessedByRole/SanitizeForRole
+// restrictions for one patient until it expires, is revoked, or was never
+// approved. Creating one only records a pending request; ApprovedBy must be
+// set by services.EmergencyAccessService.Approve before IsActive reports true.
+type EmergencyAccessGrant struct {
+	ID         uint       `json:"id" gorm:"primaryKey;type:bigint unsigned;autoIncrement"`
+	UserID     uint       `json:"user_id" gorm:"not null;index;type:bigint unsigned"`
+	PatientID  uint       `json:"patient_id" gorm:"not null;index;type:bigint unsigned"`
+	Reason     string     `json:"reason" gorm:"type:text"`
+	GrantedAt  time.Time  `json:"granted_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	ApprovedBy *uint      `json:"approved_by" gorm:"type:bigint unsigned"`
+}
+
+func (g *EmergencyAccessGrant) BeforeCreate(tx *gorm.DB) (err error) {
+	if g.GrantedAt.IsZero() {
+		g.GrantedAt = time.Now()
+	}
+	return
+}
+
+// IsApproved reports whether the grant has cleared the approval step.
+func (g *EmergencyAccessGrant) IsApproved() bool {
+	return g.ApprovedBy != nil
+}
+
+// IsActive reports whether g currently authorizes a break-glass read: it
+// must be approved, not revoked, and not past ExpiresAt.
+func (g *EmergencyAccessGrant) IsActive() bool {
+	if !g.IsApproved() || g.RevokedAt != nil {
+		return false
+	}
+	return time.Now().Before(g.ExpiresAt)
+}
+
+func (g *EmergencyAccessGrant) TableName() string {
+	return "emergency_access_grants"
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// LoginAttempt records one call into UserService.Login, successful or not.
+// UserID is nil when the email didn't resolve to an account, so IP-based
+// limiting still sees (and can block) credential stuffing against unknown
+// emails. services.LoginAttemptTracker is the only writer and reader of this
+// table.
+type LoginAttempt struct {
+	ID        uint      `json:"id" gorm:"primaryKey;type:bigint unsigned;autoIncrement"`
+	UserID    *uint     `json:"user_id" gorm:"index;type:bigint unsigned"`
+	IP        string    `json:"ip" gorm:"type:varchar(64);index"`
+	AttemptAt time.Time `json:"attempt_at" gorm:"index"`
+	Success   bool      `json:"success"`
+	UserAgent string    `json:"user_agent" gorm:"type:varchar(255)"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"healthsecure/internal/policy"
+
 	"gorm.io/gorm"
 )
 
@@ -53,17 +55,29 @@ func (mr *MedicalRecord) IsHighSeverity() bool {
 	return mr.Severity == SeverityHigh || mr.Severity == SeverityCritical
 }
 
+// CanBeAccessedByRole is a thin wrapper around the ABAC policy engine
+// (internal/policy), kept for callers that only have a role and no further
+// request context. The record's severity doubles as the resource's
+// sensitivity attribute.
 func (mr *MedicalRecord) CanBeAccessedByRole(role UserRole, userID uint) bool {
-	switch role {
-	case RoleDoctor:
+	decision := policy.DefaultEngine.Decide(
+		policy.Context{Now: time.Now()},
+		policy.Subject{Role: string(role)},
+		policy.Resource{Type: "patient", Sensitivity: string(mr.Severity)},
+		policy.ActionRead,
+	)
+	return decision.Permitted()
+}
+
+// CanBeAccessedByRoleOrGrant is CanBeAccessedByRole, except an active
+// break-glass grant (services.EmergencyAccessService.IsActive(userID,
+// mr.PatientID)) always permits the read. Callers that bypass the normal
+// check this way must still log it via EmergencyAccessService.LogOverrideAccess.
+func (mr *MedicalRecord) CanBeAccessedByRoleOrGrant(role UserRole, userID uint, emergencyActive bool) bool {
+	if emergencyActive {
 		return true
-	case RoleNurse:
-		return mr.Severity != SeverityCritical
-	case RoleAdmin:
-		return false
-	default:
-		return false
 	}
+	return mr.CanBeAccessedByRole(role, userID)
 }
 
 func (mr *MedicalRecord) SanitizeForRole(role UserRole) *MedicalRecord {
@@ -83,6 +97,18 @@ func (mr *MedicalRecord) SanitizeForRole(role UserRole) *MedicalRecord {
 	return &sanitized
 }
 
+// SanitizeForAccess is SanitizeForRole, except an active break-glass grant
+// lets a doctor or nurse see the un-redacted record (diagnosis, treatment,
+// medications) that SanitizeForRole would otherwise blank out for a nurse
+// viewing a SeverityCritical record.
+func (mr *MedicalRecord) SanitizeForAccess(role UserRole, emergencyActive bool) *MedicalRecord {
+	if emergencyActive && (role == RoleDoctor || role == RoleNurse) {
+		sanitized := *mr
+		return &sanitized
+	}
+	return mr.SanitizeForRole(role)
+}
+
 func (mr *MedicalRecord) TableName() string {
 	return "medical_records"
-}
\ No newline at end of file
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// UserTOTPSecret stores the RFC 6238 shared secret for a user's authenticator
+// app enrollment. The secret is base32-encoded, as TOTP apps expect.
+type UserTOTPSecret struct {
+	ID         uint       `json:"id" gorm:"primaryKey;type:bigint unsigned;autoIncrement"`
+	UserID     uint       `json:"user_id" gorm:"not null;uniqueIndex;type:bigint unsigned"`
+	Secret     string     `json:"-" gorm:"type:varchar(64);not null"`
+	Confirmed  bool       `json:"confirmed" gorm:"default:false"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+func (t *UserTOTPSecret) TableName() string {
+	return "user_totp_secrets"
+}
+
+// UserWebAuthnCredential stores one registered WebAuthn authenticator for a
+// user, as returned by go-webauthn/webauthn after a successful registration.
+type UserWebAuthnCredential struct {
+	ID              uint      `json:"id" gorm:"primaryKey;type:bigint unsigned;autoIncrement"`
+	UserID          uint      `json:"user_id" gorm:"not null;index;type:bigint unsigned"`
+	CredentialID    string    `json:"credential_id" gorm:"type:varchar(255);unique;not null"`
+	PublicKey       []byte    `json:"-" gorm:"type:blob;not null"`
+	AttestationType string    `json:"attestation_type" gorm:"type:varchar(32)"`
+	AAGUID          string    `json:"aaguid" gorm:"type:varchar(64)"`
+	SignCount       uint32    `json:"sign_count"`
+	Transports      string    `json:"transports" gorm:"type:varchar(255)"` // space-separated
+	CreatedAt       time.Time `json:"created_at"`
+	LastUsedAt      *time.Time `json:"last_used_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+func (c *UserWebAuthnCredential) TableName() string {
+	return "user_webauthn_credentials"
+}
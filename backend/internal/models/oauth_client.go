@@ -0,0 +1,89 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type OAuthGrantType string
+
+const (
+	GrantAuthorizationCode OAuthGrantType = "authorization_code"
+	GrantRefreshToken      OAuthGrantType = "refresh_token"
+	GrantClientCredentials OAuthGrantType = "client_credentials"
+)
+
+// OAuthClient is a registered third-party integration (EHR gateway, mobile
+// app, lab system) allowed to obtain tokens against HealthSecure.
+type OAuthClient struct {
+	ID                uint      `json:"id" gorm:"primaryKey;type:bigint unsigned;autoIncrement"`
+	ClientID          string    `json:"client_id" gorm:"type:varchar(64);unique;not null;index"`
+	ClientSecretHash  string    `json:"-" gorm:"type:varchar(255);not null"`
+	Name              string    `json:"name" gorm:"type:varchar(191);not null"`
+	RedirectURIs      string    `json:"redirect_uris" gorm:"type:text"`  // space-separated
+	AllowedScopes     string    `json:"allowed_scopes" gorm:"type:text"` // space-separated
+	AllowedGrantTypes string    `json:"allowed_grant_types" gorm:"type:text"` // space-separated
+	Active            bool      `json:"active" gorm:"default:true"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+func (c *OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+func (c *OAuthClient) Scopes() []string {
+	return strings.Fields(c.AllowedScopes)
+}
+
+func (c *OAuthClient) HasScope(scope string) bool {
+	for _, s := range c.Scopes() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *OAuthClient) HasGrantType(grant OAuthGrantType) bool {
+	for _, g := range strings.Fields(c.AllowedGrantTypes) {
+		if g == string(grant) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *OAuthClient) RedirectURIAllowed(uri string) bool {
+	for _, u := range strings.Fields(c.RedirectURIs) {
+		if u == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// OAuthAuthorizationCode is a short-lived authorization_code grant with PKCE.
+type OAuthAuthorizationCode struct {
+	ID                  uint      `json:"id" gorm:"primaryKey;type:bigint unsigned;autoIncrement"`
+	Code                string    `json:"-" gorm:"type:varchar(128);unique;not null;index"`
+	ClientID            string    `json:"client_id" gorm:"type:varchar(64);not null;index"`
+	UserID              uint      `json:"user_id" gorm:"not null;type:bigint unsigned"`
+	RedirectURI         string    `json:"redirect_uri" gorm:"type:varchar(2048);not null"`
+	Scope               string    `json:"scope" gorm:"type:varchar(512)"`
+	CodeChallenge       string    `json:"-" gorm:"type:varchar(128)"`
+	CodeChallengeMethod string    `json:"-" gorm:"type:varchar(16)"`
+	Used                bool      `json:"used" gorm:"default:false"`
+	ExpiresAt           time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt           time.Time `json:"created_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+func (c *OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+func (c *OAuthAuthorizationCode) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
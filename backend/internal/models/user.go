@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"healthsecure/internal/policy"
+
 	"gorm.io/gorm"
 )
 
@@ -12,18 +14,20 @@ const (
 	RoleDoctor UserRole = "doctor"
 	RoleNurse  UserRole = "nurse"
 	RoleAdmin  UserRole = "admin"
+	RoleDevice UserRole = "device" // bedside tablets, lab instruments enrolled via internal/enrollment
 )
 
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey;type:bigint unsigned;autoIncrement"`
-	Email     string    `json:"email" gorm:"type:varchar(191);unique;not null;index"`
-	Password  string    `json:"-" gorm:"type:varchar(255);not null"`
-	Role      UserRole  `json:"role" gorm:"not null;type:enum('doctor','nurse','admin')"`
-	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
-	Active    bool      `json:"active" gorm:"default:true"`
+	ID        uint       `json:"id" gorm:"primaryKey;type:bigint unsigned;autoIncrement"`
+	Email     string     `json:"email" gorm:"type:varchar(191);unique;not null;index"`
+	Password  string     `json:"-" gorm:"type:varchar(255);not null"`
+	Role      UserRole   `json:"role" gorm:"not null;type:enum('doctor','nurse','admin','device')"`
+	Name      string     `json:"name" gorm:"type:varchar(100);not null"`
+	Active    bool       `json:"active" gorm:"default:true"`
 	LastLogin *time.Time `json:"last_login"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	LockedAt  *time.Time `json:"locked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
@@ -53,14 +57,43 @@ func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
 
+// IsLocked reports whether services.LoginAttemptTracker has locked this
+// account after too many consecutive failed logins; it stays locked until
+// an admin (or a completed email-based reset) clears LockedAt.
+func (u *User) IsLocked() bool {
+	return u.LockedAt != nil
+}
+
+// CanAccessPatientData is a thin wrapper around the ABAC policy engine
+// (internal/policy) kept for callers that only have a role to check and no
+// ward/shift/emergency context. Handlers that have that context should call
+// policy.DefaultEngine.Decide directly for a ward- and shift-aware decision.
 func (u *User) CanAccessPatientData() bool {
-	return u.Role == RoleDoctor || u.Role == RoleNurse
+	decision := policy.DefaultEngine.Decide(
+		policy.Context{Now: time.Now()},
+		policy.Subject{Role: string(u.Role)},
+		policy.Resource{Type: "patient"},
+		policy.ActionRead,
+	)
+	return decision.Permitted()
 }
 
 func (u *User) CanAccessSensitiveData() bool {
-	return u.Role == RoleDoctor
+	decision := policy.DefaultEngine.Decide(
+		policy.Context{Now: time.Now()},
+		policy.Subject{Role: string(u.Role)},
+		policy.Resource{Type: "patient", Sensitivity: "sensitive"},
+		policy.ActionRead,
+	)
+	return decision.Permitted()
 }
 
 func (u *User) CanManageUsers() bool {
-	return u.Role == RoleAdmin
-}
\ No newline at end of file
+	decision := policy.DefaultEngine.Decide(
+		policy.Context{Now: time.Now()},
+		policy.Subject{Role: string(u.Role)},
+		policy.Resource{Type: "user"},
+		policy.ActionWrite,
+	)
+	return decision.Permitted()
+}
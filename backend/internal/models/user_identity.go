@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// UserIdentity links a User row to an external SSO identity (Google,
+// Microsoft/Entra, Okta, or a generic OIDC provider), so one clinician can
+// authenticate through more than one identity provider and always land on
+// the same account.
+type UserIdentity struct {
+	ID       uint      `json:"id" gorm:"primaryKey;type:bigint unsigned;autoIncrement"`
+	UserID   uint      `json:"user_id" gorm:"not null;index;type:bigint unsigned"`
+	Provider string    `json:"provider" gorm:"type:varchar(64);not null;uniqueIndex:idx_provider_subject"`
+	Subject  string    `json:"subject" gorm:"type:varchar(191);not null;uniqueIndex:idx_provider_subject"`
+	LinkedAt time.Time `json:"linked_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,71 @@
+package phi
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// BloomFilter is a fixed-size, standard-library-only Bloom filter: no false
+// negatives, a tunable false-positive rate. It backs NameRegistry rather than
+// keeping every patient name/identifier in memory as a plain set.
+type BloomFilter struct {
+	bits []bool
+	k    int
+}
+
+// NewBloomFilter sizes a filter for expectedItems entries at falsePositiveRate,
+// using the standard m = -(n*ln(p))/(ln(2)^2) and k = (m/n)*ln(2) formulas.
+func NewBloomFilter(expectedItems int, falsePositiveRate float64) *BloomFilter {
+	if expectedItems < 1 {
+		expectedItems = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	n := float64(expectedItems)
+	m := math.Ceil(-(n * math.Log(falsePositiveRate)) / (math.Ln2 * math.Ln2))
+	k := int(math.Round((m / n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &BloomFilter{bits: make([]bool, int(m)), k: k}
+}
+
+// Add records item as present.
+func (b *BloomFilter) Add(item string) {
+	for _, idx := range b.indices(item) {
+		b.bits[idx] = true
+	}
+}
+
+// Test reports whether item may be present (true positives and occasional
+// false positives only - never a false negative).
+func (b *BloomFilter) Test(item string) bool {
+	for _, idx := range b.indices(item) {
+		if !b.bits[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// indices derives b.k bit positions from two independent hashes via double
+// hashing (Kirsch-Mitzenmacher), avoiding k separate hash functions.
+func (b *BloomFilter) indices(item string) []int {
+	h1 := fnv.New64a()
+	h1.Write([]byte(item))
+	sum1 := h1.Sum64()
+
+	h2 := fnv.New64()
+	h2.Write([]byte(item))
+	sum2 := h2.Sum64()
+
+	m := uint64(len(b.bits))
+	indices := make([]int, b.k)
+	for i := 0; i < b.k; i++ {
+		indices[i] = int((sum1 + uint64(i)*sum2) % m)
+	}
+	return indices
+}
@@ -0,0 +1,105 @@
+package phi
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	ssnPattern   = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	phonePattern = regexp.MustCompile(`\+?\d{1,3}[-.\s]?\(?\d{3}\)?[-.\s]?\d{3}[-.\s]?\d{4}\b`)
+	emailPattern = regexp.MustCompile(`\b[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}\b`)
+	mrnPattern   = regexp.MustCompile(`(?i)\bMRN[:\s#]*(\d{6,10})\b`)
+	datePattern  = regexp.MustCompile(`\b(\d{4}-\d{2}-\d{2}|\d{1,2}/\d{1,2}/\d{2,4})\b`)
+
+	// icdPattern matches ICD-10-CM codes (a letter, two digits, an optional
+	// decimal suffix). cptPattern matches a 5-digit CPT code, but - like
+	// mrnPattern - only when preceded by code-identifying context: a bare
+	// 5-digit span on its own is just as likely to be a partial account
+	// number, a lab value, or a short patient/device ID, and allowlisting
+	// it unconditionally would let that real PHI slip through unredacted.
+	// Both are allowlisted once matched - they're clinical codes, not
+	// identifiers.
+	icdPattern = regexp.MustCompile(`\b[A-TV-Z][0-9][0-9AB](?:\.[0-9A-TV-Z]{1,4})?\b`)
+	cptPattern = regexp.MustCompile(`(?i)\b(?:CPT|procedure code|diagnosis code|billing code|code)[:\s#]*(\d{5})\b`)
+)
+
+func detectSSN(text string) []Finding {
+	return matchesToFindings(ssnPattern.FindAllStringIndex(text, -1), KindSSN, 0.95)
+}
+
+func detectPhone(text string) []Finding {
+	return matchesToFindings(phonePattern.FindAllStringIndex(text, -1), KindPhone, 0.85)
+}
+
+func detectEmail(text string) []Finding {
+	return matchesToFindings(emailPattern.FindAllStringIndex(text, -1), KindEmail, 0.95)
+}
+
+// detectMRN only flags the digits after the MRN label, not the label itself.
+func detectMRN(text string) []Finding {
+	var findings []Finding
+	for _, m := range mrnPattern.FindAllStringSubmatchIndex(text, -1) {
+		findings = append(findings, Finding{Kind: KindMRN, Start: m[2], End: m[3], Confidence: 0.9})
+	}
+	return findings
+}
+
+func detectDate(text string) []Finding {
+	return matchesToFindings(datePattern.FindAllStringIndex(text, -1), KindDate, 0.6)
+}
+
+// detectCodes returns allowlisted ICD/CPT spans so the caller can suppress
+// overlapping findings from the other detectors instead of redacting them.
+// Like detectMRN, only the digits themselves are flagged, not the
+// code-identifying label cptPattern requires in front of them.
+func detectCodes(text string) []Finding {
+	var findings []Finding
+	findings = append(findings, matchesToFindings(icdPattern.FindAllStringIndex(text, -1), KindCode, 1.0)...)
+	for _, m := range cptPattern.FindAllStringSubmatchIndex(text, -1) {
+		findings = append(findings, Finding{Kind: KindCode, Start: m[2], End: m[3], Confidence: 1.0})
+	}
+	return findings
+}
+
+// detectKnownNames tokenizes text on whitespace/punctuation and flags any
+// token the name registry recognizes as a known patient name or identifier.
+func detectKnownNames(text string, names NameChecker) []Finding {
+	var findings []Finding
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		token := text[start:end]
+		if names.Contains(strings.ToLower(token)) {
+			findings = append(findings, Finding{Kind: KindName, Start: start, End: end, Confidence: 0.75})
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+		} else {
+			flush(i)
+		}
+	}
+	flush(len(text))
+
+	return findings
+}
+
+func isWordRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '\''
+}
+
+func matchesToFindings(matches [][]int, kind Kind, confidence float64) []Finding {
+	findings := make([]Finding, 0, len(matches))
+	for _, m := range matches {
+		findings = append(findings, Finding{Kind: kind, Start: m[0], End: m[1], Confidence: confidence})
+	}
+	return findings
+}
@@ -0,0 +1,78 @@
+package phi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectSSN(t *testing.T) {
+	findings := detectSSN("Patient SSN is 123-45-6789 on file.")
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, KindSSN, findings[0].Kind)
+	}
+}
+
+func TestDetectEmail(t *testing.T) {
+	findings := detectEmail("Contact jane.doe@example.com for records.")
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, KindEmail, findings[0].Kind)
+	}
+}
+
+func TestDetectMRN_OnlyFlagsDigits(t *testing.T) {
+	text := "MRN: 1234567 confirmed"
+	findings := detectMRN(text)
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, "1234567", text[findings[0].Start:findings[0].End])
+	}
+}
+
+func TestDetectCodes_AllowlistsICDAndCPT(t *testing.T) {
+	findings := detectCodes("Diagnosis E11.9, billed under CPT 99213.")
+	assert.NotEmpty(t, findings)
+	for _, f := range findings {
+		assert.Equal(t, KindCode, f.Kind)
+	}
+}
+
+func TestDetectCodes_RequiresCodeContextForBareDigits(t *testing.T) {
+	// A bare 5-digit span with no preceding code label (account number, lab
+	// value, short ID, ...) must not be allowlisted as a clinical code.
+	findings := detectCodes("Account 48213 was charged on file.")
+	assert.Empty(t, findings)
+}
+
+type mapNameChecker map[string]bool
+
+func (m mapNameChecker) Contains(token string) bool { return m[token] }
+
+func TestDefaultRedactor_RedactsKnownNameButNotAllowlistedCode(t *testing.T) {
+	names := mapNameChecker{"smith": true}
+	r := NewDefaultRedactor(names, nil)
+
+	redacted, findings := r.Redact(context.Background(), "Patient Smith has diagnosis code 99213 on file.")
+
+	assert.Contains(t, redacted, "[REDACTED:NAME]")
+	assert.Contains(t, redacted, "99213")
+	assert.NotEmpty(t, findings)
+}
+
+func TestDefaultRedactor_SuppressesOverlappingFindings(t *testing.T) {
+	r := NewDefaultRedactor(nil, nil)
+
+	_, findings := r.Redact(context.Background(), "Call 555-123-4567 today.")
+
+	for i := 1; i < len(findings); i++ {
+		assert.GreaterOrEqual(t, findings[i].Start, findings[i-1].End)
+	}
+}
+
+func TestBloomFilter_NoFalseNegatives(t *testing.T) {
+	b := NewBloomFilter(100, 0.01)
+	b.Add("johndoe")
+
+	assert.True(t, b.Test("johndoe"))
+	assert.False(t, b.Test("unrelated-token"))
+}
@@ -0,0 +1,31 @@
+package phi
+
+import "context"
+
+// NERServiceClient is the subset of a generated gRPC de-identification
+// client that GRPCNERClient depends on. Swap in the real generated client
+// from the de-id model's .proto once one exists; until then NewGRPCNERClient
+// can be given any implementation (including a test fake).
+type NERServiceClient interface {
+	DetectPHI(ctx context.Context, text string) ([]Finding, error)
+}
+
+// GRPCNERClient adapts a NERServiceClient to the NERClient interface
+// DefaultRedactor expects, so the external de-id model is just another
+// pluggable stage rather than something the redactor calls directly.
+type GRPCNERClient struct {
+	client NERServiceClient
+}
+
+// NewGRPCNERClient wraps client as an NERClient. client is typically a
+// generated gRPC stub's connection wrapper.
+func NewGRPCNERClient(client NERServiceClient) *GRPCNERClient {
+	return &GRPCNERClient{client: client}
+}
+
+func (c *GRPCNERClient) Detect(ctx context.Context, text string) ([]Finding, error) {
+	if c.client == nil {
+		return nil, nil
+	}
+	return c.client.DetectPHI(ctx, text)
+}
@@ -0,0 +1,167 @@
+// Package phi redacts protected health information from free text before it
+// leaves the system — the AI-safe patient view and the assistant's streamed
+// responses both run through it rather than each inventing their own
+// scrubbing.
+package phi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Kind identifies what a Finding matched.
+type Kind string
+
+const (
+	KindSSN   Kind = "SSN"
+	KindPhone Kind = "PHONE"
+	KindEmail Kind = "EMAIL"
+	KindMRN   Kind = "MRN"
+	KindDate  Kind = "DATE"
+	KindCode  Kind = "CODE" // ICD/CPT, allowlisted - never redacted
+	KindName  Kind = "NAME"
+)
+
+// Finding is one span of text a detector flagged, with Start/End as byte
+// offsets into the original string (Go slice semantics: text[Start:End]).
+type Finding struct {
+	Kind       Kind
+	Start      int
+	End        int
+	Confidence float64
+}
+
+// NameChecker reports whether a token is a known patient name or identifier.
+// NameRegistry is the production implementation; tests can supply a map-based
+// stub instead of standing up a Bloom filter.
+type NameChecker interface {
+	Contains(token string) bool
+}
+
+// NERClient is a pluggable hook for an external de-identification model.
+// GRPCNERClient is the production implementation; passing a nil NERClient to
+// NewDefaultRedactor disables this stage entirely.
+type NERClient interface {
+	Detect(ctx context.Context, text string) ([]Finding, error)
+}
+
+// Redactor replaces PHI spans in text with [REDACTED:KIND] tokens and
+// reports what it found, so callers can log a count without logging the PHI
+// itself.
+type Redactor interface {
+	Redact(ctx context.Context, text string) (string, []Finding)
+}
+
+// DefaultRedactor runs the three-stage pipeline this package exists for:
+// regex detectors, a Bloom-filter name/identifier check, and an optional NER
+// hook. Each stage only adds findings; allowlisted code spans (ICD/CPT) are
+// subtracted back out before redaction so clinical codes aren't mistaken for
+// patient identifiers.
+type DefaultRedactor struct {
+	names NameChecker
+	ner   NERClient
+}
+
+// NewDefaultRedactor builds a DefaultRedactor. names may be nil to skip the
+// Bloom-filter stage (e.g. before NameRegistry.Load has run); ner may be nil
+// to skip the NER stage entirely.
+func NewDefaultRedactor(names NameChecker, ner NERClient) *DefaultRedactor {
+	return &DefaultRedactor{names: names, ner: ner}
+}
+
+// Redact runs the pipeline and returns the redacted text alongside every
+// Finding that survived allowlisting, sorted by Start.
+func (r *DefaultRedactor) Redact(ctx context.Context, text string) (string, []Finding) {
+	var findings []Finding
+	findings = append(findings, detectSSN(text)...)
+	findings = append(findings, detectPhone(text)...)
+	findings = append(findings, detectEmail(text)...)
+	findings = append(findings, detectMRN(text)...)
+	findings = append(findings, detectDate(text)...)
+	codes := detectCodes(text)
+
+	if r.names != nil {
+		findings = append(findings, detectKnownNames(text, r.names)...)
+	}
+
+	if r.ner != nil {
+		if nerFindings, err := r.ner.Detect(ctx, text); err == nil {
+			findings = append(findings, nerFindings...)
+		}
+	}
+
+	findings = suppressAllowlisted(findings, codes)
+	findings = dedupeOverlapping(findings)
+
+	return applyRedactions(text, findings), findings
+}
+
+// suppressAllowlisted drops any finding whose span overlaps an allowlisted
+// code, so e.g. a 5-digit CPT code that the name registry also matches isn't
+// reported (and redacted) as a NAME.
+func suppressAllowlisted(findings, codes []Finding) []Finding {
+	if len(codes) == 0 {
+		return findings
+	}
+
+	var kept []Finding
+	for _, f := range findings {
+		overlapped := false
+		for _, c := range codes {
+			if f.Start < c.End && c.Start < f.End {
+				overlapped = true
+				break
+			}
+		}
+		if !overlapped {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// dedupeOverlapping sorts findings by Start and, where two findings overlap,
+// keeps only the higher-confidence one (ties keep the first/earlier-staged
+// detector, since regex detectors run before the Bloom/NER stages).
+func dedupeOverlapping(findings []Finding) []Finding {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Start != findings[j].Start {
+			return findings[i].Start < findings[j].Start
+		}
+		return findings[i].Confidence > findings[j].Confidence
+	})
+
+	var kept []Finding
+	for _, f := range findings {
+		if len(kept) > 0 {
+			last := kept[len(kept)-1]
+			if f.Start < last.End {
+				if f.Confidence > last.Confidence {
+					kept[len(kept)-1] = f
+				}
+				continue
+			}
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// applyRedactions replaces every finding's span with a [REDACTED:KIND]
+// token. findings must already be sorted and non-overlapping.
+func applyRedactions(text string, findings []Finding) string {
+	if len(findings) == 0 {
+		return text
+	}
+
+	var out []byte
+	cursor := 0
+	for _, f := range findings {
+		out = append(out, text[cursor:f.Start]...)
+		out = append(out, fmt.Sprintf("[REDACTED:%s]", f.Kind)...)
+		cursor = f.End
+	}
+	out = append(out, text[cursor:]...)
+	return string(out)
+}
@@ -0,0 +1,78 @@
+package phi
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"healthsecure/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// NameRegistry is the production NameChecker: a Bloom filter of patient names
+// and identifiers. Load builds it from the patients table and Refresh adds one
+// patient's tokens without a full reload; the registry runs neither on its
+// own, so it holds only what its owner has loaded or refreshed.
+type NameRegistry struct {
+	db *gorm.DB
+
+	mu    sync.RWMutex
+	bloom *BloomFilter
+}
+
+// NewNameRegistry creates an empty NameRegistry; call Load before serving
+// traffic so Contains has something to check against.
+func NewNameRegistry(db *gorm.DB) *NameRegistry {
+	return &NameRegistry{db: db, bloom: NewBloomFilter(1, 0.01)}
+}
+
+// Load replaces the filter with a fresh one sized for the current patients
+// table and populates it from every patient's name and identifiers.
+func (n *NameRegistry) Load() error {
+	var patients []models.Patient
+	if err := n.db.Find(&patients).Error; err != nil {
+		return fmt.Errorf("failed to load patients for PHI name registry: %w", err)
+	}
+
+	bloom := NewBloomFilter(len(patients)*3+1, 0.01)
+	for _, p := range patients {
+		addPatientTokens(bloom, p)
+	}
+
+	n.mu.Lock()
+	n.bloom = bloom
+	n.mu.Unlock()
+
+	return nil
+}
+
+// Refresh adds a single patient's tokens to the live filter, so a newly
+// created or updated patient is recognized without waiting for the next
+// full Load.
+func (n *NameRegistry) Refresh(p models.Patient) {
+	n.mu.RLock()
+	bloom := n.bloom
+	n.mu.RUnlock()
+
+	addPatientTokens(bloom, p)
+}
+
+// Contains reports whether token (expected lowercased) matches a known
+// patient name or identifier.
+func (n *NameRegistry) Contains(token string) bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.bloom.Test(token)
+}
+
+// addPatientTokens indexes a patient's name parts and MRN so Contains can
+// match on any single token, not just the full name.
+func addPatientTokens(bloom *BloomFilter, p models.Patient) {
+	for _, part := range strings.Fields(strings.ToLower(p.GetFullName())) {
+		bloom.Add(part)
+	}
+	if p.MRN != "" {
+		bloom.Add(strings.ToLower(p.MRN))
+	}
+}
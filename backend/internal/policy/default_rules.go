@@ -0,0 +1,92 @@
+package policy
+
+// DefaultEngine reproduces HealthSecure's pre-ABAC behavior (the old
+// User.CanAccess* role checks) while adding the ward/shift and emergency
+// override policies hospital IT actually asked for. Handlers that need
+// finer-grained decisions should build their own Engine with NewEngine;
+// DefaultEngine exists so models.User's backward-compatible wrapper methods
+// have something to call.
+var DefaultEngine = NewEngine(DefaultRules())
+
+// DefaultRules returns HealthSecure's baseline rule set.
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:          "admin-manage-users",
+			Effect:        Permit,
+			Roles:         []string{"admin"},
+			ResourceTypes: []string{"user"},
+		},
+		{
+			// A clinician with a live emergency-access token may read any
+			// patient record regardless of ward/shift, but must accept the
+			// audit obligation.
+			Name:                  "emergency-access-read",
+			Effect:                Permit,
+			Roles:                 []string{"doctor", "nurse"},
+			Actions:               []Action{ActionRead},
+			ResourceTypes:         []string{"patient"},
+			RequireEmergencyToken: true,
+			Obligations:           []string{"must_audit_as_emergency"},
+		},
+		{
+			// Outside business hours, non-admins may only read - charting
+			// and record changes wait for the next shift. The emergency-access
+			// rule above permits reads only, so it does not lift this.
+			Name:              "after-hours-write-restricted",
+			Effect:            Deny,
+			Roles:             []string{"doctor", "nurse"},
+			Actions:           []Action{ActionWrite, ActionDelete},
+			RequireAfterHours: true,
+		},
+		{
+			// Nurses never see critical-severity records or data flagged
+			// "sensitive", regardless of ward or shift - only an emergency
+			// token (handled above) overrides this.
+			Name:          "nurse-deny-critical",
+			Effect:        Deny,
+			Roles:         []string{"nurse"},
+			Actions:       []Action{ActionRead, ActionSearch},
+			Sensitivities: []string{"critical", "sensitive"},
+		},
+		{
+			Name:               "nurse-ward-read",
+			Effect:             Permit,
+			Roles:              []string{"nurse"},
+			Actions:            []Action{ActionRead, ActionSearch},
+			ResourceTypes:      []string{"patient"},
+			RequireSameWard:    true,
+			RequireWithinShift: true,
+		},
+		{
+			Name:          "doctor-sensitive-read",
+			Effect:        Permit,
+			Roles:         []string{"doctor"},
+			Actions:       []Action{ActionRead},
+			Sensitivities: []string{"sensitive", "critical"},
+		},
+		{
+			Name:          "doctor-patient-access",
+			Effect:        Permit,
+			Roles:         []string{"doctor"},
+			Actions:       []Action{ActionRead, ActionSearch, ActionWrite},
+			ResourceTypes: []string{"patient"},
+		},
+		{
+			// Fallback for resources with no ward attribute at all (e.g. the
+			// zero-arg User.CanAccessPatientData wrapper, which has no
+			// resource to attach a ward to) - reproduces the old "any nurse
+			// can read any patient" behavior for those callers only. A
+			// resource that does carry a WardID must go through
+			// nurse-ward-read above instead: this rule must never become a
+			// blanket permit that masks that restriction for a nurse
+			// reading a real, ward-tagged patient in a different ward.
+			Name:          "nurse-patient-read-fallback",
+			Effect:        Permit,
+			Roles:         []string{"nurse"},
+			Actions:       []Action{ActionRead, ActionSearch},
+			ResourceTypes: []string{"patient"},
+			RequireNoWard: true,
+		},
+	}
+}
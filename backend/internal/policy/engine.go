@@ -0,0 +1,120 @@
+package policy
+
+// Rule is a single declarative ABAC rule. Every non-empty/non-zero
+// constraint must hold for the rule to match; an empty slice or zero value
+// means "don't care" for that attribute. Rules are evaluated in order and
+// the first match decides the request, so more specific or more urgent
+// rules (emergency overrides, after-hours restrictions) should come first.
+type Rule struct {
+	Name   string
+	Effect Effect
+
+	Roles         []string
+	Actions       []Action
+	ResourceTypes []string
+	Sensitivities []string
+
+	RequireSameWard       bool
+	RequireNoWard         bool
+	RequireWithinShift    bool
+	RequireAfterHours     bool
+	RequireEmergencyToken bool
+	MinACR                string
+
+	Obligations []string
+}
+
+// acrLevel orders assurance levels so MinACR can be compared against a
+// subject's ACR. Unknown values are treated as the lowest level.
+var acrLevel = map[string]int{
+	"":               0,
+	"urn:hs:acr:pwd": 1,
+	"urn:hs:acr:mfa": 2,
+}
+
+func (r Rule) matches(subject Subject, resource Resource, action Action, ctx Context) bool {
+	if len(r.Roles) > 0 && !containsString(r.Roles, subject.Role) {
+		return false
+	}
+	if len(r.Actions) > 0 && !containsAction(r.Actions, action) {
+		return false
+	}
+	if len(r.ResourceTypes) > 0 && !containsString(r.ResourceTypes, resource.Type) {
+		return false
+	}
+	if len(r.Sensitivities) > 0 && !containsString(r.Sensitivities, resource.Sensitivity) {
+		return false
+	}
+	if r.RequireSameWard && (subject.WardID == "" || subject.WardID != resource.WardID) {
+		return false
+	}
+	if r.RequireNoWard && resource.WardID != "" {
+		return false
+	}
+	if r.RequireWithinShift && !withinShift(subject, ctx) {
+		return false
+	}
+	if r.RequireAfterHours && !isAfterHours(ctx) {
+		return false
+	}
+	if r.RequireEmergencyToken && ctx.EmergencyToken == "" {
+		return false
+	}
+	if r.MinACR != "" && acrLevel[subject.ACR] < acrLevel[r.MinACR] {
+		return false
+	}
+	return true
+}
+
+func withinShift(subject Subject, ctx Context) bool {
+	if subject.ShiftStart.IsZero() || subject.ShiftEnd.IsZero() {
+		return false
+	}
+	now := ctx.Now
+	return !now.Before(subject.ShiftStart) && now.Before(subject.ShiftEnd)
+}
+
+// isAfterHours treats 07:00-21:00 local time as normal business hours.
+func isAfterHours(ctx Context) bool {
+	hour := ctx.Now.Hour()
+	return hour < 7 || hour >= 21
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAction(haystack []Action, needle Action) bool {
+	for _, a := range haystack {
+		if a == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// Engine evaluates a Subject/Resource/Action/Context against an ordered set
+// of Rules.
+type Engine struct {
+	rules []Rule
+}
+
+func NewEngine(rules []Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// Decide returns the first matching rule's effect, or NotApplicable if no
+// rule matches — callers should treat NotApplicable as deny.
+func (e *Engine) Decide(ctx Context, subject Subject, resource Resource, action Action) Decision {
+	for _, rule := range e.rules {
+		if rule.matches(subject, resource, action, ctx) {
+			return Decision{Effect: rule.Effect, Obligations: rule.Obligations, Reason: rule.Name}
+		}
+	}
+	return Decision{Effect: NotApplicable, Reason: "no matching rule"}
+}
@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultEngine_EmergencyAccessOverridesWardRestriction(t *testing.T) {
+	nurse := Subject{Role: "nurse", WardID: "icu-3"}
+	resource := Resource{Type: "patient", WardID: "er-1"}
+
+	t.Run("DeniedWithoutEmergencyToken", func(t *testing.T) {
+		decision := DefaultEngine.Decide(Context{Now: businessHoursTime()}, nurse, resource, ActionRead)
+		assert.False(t, decision.Permitted())
+	})
+
+	t.Run("PermittedWithEmergencyTokenAndAuditObligation", func(t *testing.T) {
+		decision := DefaultEngine.Decide(Context{Now: businessHoursTime(), EmergencyToken: "em-tok-1"}, nurse, resource, ActionRead)
+		assert.True(t, decision.Permitted())
+		assert.Contains(t, decision.Obligations, "must_audit_as_emergency")
+	})
+}
+
+func TestDefaultEngine_NurseWardRestriction(t *testing.T) {
+	shiftStart := businessHoursTime().Add(-2 * time.Hour)
+	shiftEnd := businessHoursTime().Add(2 * time.Hour)
+
+	t.Run("SameWardDuringShiftIsPermitted", func(t *testing.T) {
+		nurse := Subject{Role: "nurse", WardID: "icu-3", ShiftStart: shiftStart, ShiftEnd: shiftEnd}
+		resource := Resource{Type: "patient", WardID: "icu-3"}
+
+		decision := DefaultEngine.Decide(Context{Now: businessHoursTime()}, nurse, resource, ActionRead)
+		assert.True(t, decision.Permitted())
+	})
+
+	t.Run("DifferentWardIsDenied", func(t *testing.T) {
+		nurse := Subject{Role: "nurse", WardID: "icu-3", ShiftStart: shiftStart, ShiftEnd: shiftEnd}
+		resource := Resource{Type: "patient", WardID: "er-1"}
+
+		decision := DefaultEngine.Decide(Context{Now: businessHoursTime()}, nurse, resource, ActionRead)
+		assert.False(t, decision.Permitted())
+	})
+
+	t.Run("NoWardResourceFallsBackToGeneralReadPermission", func(t *testing.T) {
+		nurse := Subject{Role: "nurse", WardID: "icu-3", ShiftStart: shiftStart, ShiftEnd: shiftEnd}
+		resource := Resource{Type: "patient"}
+
+		decision := DefaultEngine.Decide(Context{Now: businessHoursTime()}, nurse, resource, ActionRead)
+		assert.True(t, decision.Permitted())
+		assert.Equal(t, "nurse-patient-read-fallback", decision.Reason)
+	})
+
+	t.Run("CriticalRecordIsDeniedEvenInWard", func(t *testing.T) {
+		nurse := Subject{Role: "nurse", WardID: "icu-3", ShiftStart: shiftStart, ShiftEnd: shiftEnd}
+		resource := Resource{Type: "patient", WardID: "icu-3", Sensitivity: "critical"}
+
+		decision := DefaultEngine.Decide(Context{Now: businessHoursTime()}, nurse, resource, ActionRead)
+		assert.False(t, decision.Permitted())
+	})
+}
+
+func TestDefaultEngine_AfterHoursReadOnlyMode(t *testing.T) {
+	doctor := Subject{Role: "doctor"}
+	resource := Resource{Type: "patient"}
+
+	t.Run("WriteIsDeniedAfterHours", func(t *testing.T) {
+		decision := DefaultEngine.Decide(Context{Now: afterHoursTime()}, doctor, resource, ActionWrite)
+		assert.False(t, decision.Permitted())
+	})
+
+	t.Run("ReadIsStillPermittedAfterHours", func(t *testing.T) {
+		decision := DefaultEngine.Decide(Context{Now: afterHoursTime()}, doctor, resource, ActionRead)
+		assert.True(t, decision.Permitted())
+	})
+
+	t.Run("WriteIsPermittedDuringBusinessHours", func(t *testing.T) {
+		decision := DefaultEngine.Decide(Context{Now: businessHoursTime()}, doctor, resource, ActionWrite)
+		assert.True(t, decision.Permitted())
+	})
+
+	t.Run("EmergencyTokenOverridesAfterHoursForReads", func(t *testing.T) {
+		nurse := Subject{Role: "nurse"}
+		decision := DefaultEngine.Decide(Context{Now: afterHoursTime(), EmergencyToken: "em-tok-2"}, nurse, resource, ActionRead)
+		assert.True(t, decision.Permitted())
+	})
+}
+
+func TestDefaultEngine_AdminCanManageUsersOnly(t *testing.T) {
+	admin := Subject{Role: "admin"}
+
+	t.Run("CanManageUsers", func(t *testing.T) {
+		decision := DefaultEngine.Decide(Context{Now: businessHoursTime()}, admin, Resource{Type: "user"}, ActionWrite)
+		assert.True(t, decision.Permitted())
+	})
+
+	t.Run("CannotReadPatientRecords", func(t *testing.T) {
+		decision := DefaultEngine.Decide(Context{Now: businessHoursTime()}, admin, Resource{Type: "patient"}, ActionRead)
+		assert.False(t, decision.Permitted())
+		assert.Equal(t, NotApplicable, decision.Effect)
+	})
+}
+
+func businessHoursTime() time.Time {
+	return time.Date(2026, 7, 20, 14, 0, 0, 0, time.UTC)
+}
+
+func afterHoursTime() time.Time {
+	return time.Date(2026, 7, 20, 2, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,69 @@
+// Package policy implements a small attribute-based access control (ABAC)
+// engine. It replaces scattered role comparisons (e.g. "nurses can read
+// patients") with declarative rules over the subject making the request, the
+// resource being acted on, the action, and ambient context like an
+// emergency-access token or the time of day.
+package policy
+
+import "time"
+
+// Action is the operation a subject is attempting on a resource.
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionWrite  Action = "write"
+	ActionSearch Action = "search"
+	ActionDelete Action = "delete"
+)
+
+// Effect is the outcome a matching Rule produces.
+type Effect string
+
+const (
+	Permit        Effect = "permit"
+	Deny          Effect = "deny"
+	NotApplicable Effect = "not_applicable"
+)
+
+// Subject describes who is making the request. Role and ACR mirror the role
+// claim of the JWT and the acr claim of a step-up token (see internal/auth);
+// neither token carries WardID or ShiftStart/ShiftEnd. Values are plain
+// strings and times here so this package has no dependency on
+// internal/models.
+type Subject struct {
+	Role       string
+	WardID     string
+	ShiftStart time.Time
+	ShiftEnd   time.Time
+	ACR        string
+}
+
+// Resource describes what is being acted on.
+type Resource struct {
+	Type        string // e.g. "patient", "record", "user"
+	WardID      string
+	Sensitivity string // e.g. "standard", "sensitive", "critical"
+}
+
+// Context carries request-time facts a rule may condition on.
+type Context struct {
+	EmergencyToken string
+	RequestIP      string
+	Now            time.Time
+}
+
+// Decision is the result of evaluating a request against the rule set.
+// Obligations are conditions the caller must satisfy for the Permit to hold,
+// e.g. "must_audit_as_emergency" requires the caller to log an emergency
+// access audit event.
+type Decision struct {
+	Effect      Effect
+	Obligations []string
+	Reason      string
+}
+
+// Permitted reports whether the request should be allowed to proceed.
+func (d Decision) Permitted() bool {
+	return d.Effect == Permit
+}
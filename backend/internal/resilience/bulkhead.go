@@ -0,0 +1,31 @@
+package resilience
+
+import "context"
+
+// Bulkhead caps the number of concurrent in-flight calls to a dependency, so
+// a slow downstream service can't exhaust the caller's own goroutines/conns
+// waiting on it.
+type Bulkhead struct {
+	slots chan struct{}
+}
+
+// NewBulkhead creates a bulkhead allowing up to maxConcurrent callers through
+// Acquire at once.
+func NewBulkhead(maxConcurrent int) *Bulkhead {
+	return &Bulkhead{slots: make(chan struct{}, maxConcurrent)}
+}
+
+// Acquire blocks until a slot is free or ctx is cancelled.
+func (b *Bulkhead) Acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees the slot acquired by a prior successful Acquire.
+func (b *Bulkhead) Release() {
+	<-b.slots
+}
@@ -0,0 +1,172 @@
+// Package resilience provides hand-rolled, dependency-free building blocks
+// (circuit breaker, retry-with-backoff, bulkhead) for guarding calls to
+// unreliable downstream services like the Python AI service, rather than
+// pulling in a library for each concern.
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// BreakerState mirrors the classic closed/open/half-open circuit breaker
+// states (as popularized by sony/gobreaker): closed lets all requests
+// through, open short-circuits them, half-open lets a trickle through to
+// probe recovery.
+type BreakerState int
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// ErrCircuitOpen is returned by Execute when the breaker is open and the
+// call is short-circuited without ever reaching fn.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerConfig controls when the breaker trips and how it recovers.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that trips the
+	// breaker from closed to open.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before allowing a
+	// half-open probe request through.
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests caps how many probe requests are allowed through
+	// while half-open, before further requests are short-circuited again.
+	HalfOpenMaxRequests int
+}
+
+// CircuitBreaker wraps calls to a flaky dependency, tripping open after
+// FailureThreshold consecutive failures and recovering through a half-open
+// probing phase. It is safe for concurrent use.
+type CircuitBreaker struct {
+	config        BreakerConfig
+	onStateChange func(from, to BreakerState)
+
+	mu               sync.Mutex
+	state            BreakerState
+	consecutiveFails int
+	openedAt         time.Time
+	halfOpenInFlight int
+}
+
+// NewCircuitBreaker builds a breaker in the closed state. onStateChange, if
+// non-nil, is invoked synchronously on every transition - callers use it to
+// surface open/half-open transitions as audit events.
+func NewCircuitBreaker(config BreakerConfig, onStateChange func(from, to BreakerState)) *CircuitBreaker {
+	return &CircuitBreaker{
+		config:        config,
+		onStateChange: onStateChange,
+		state:         StateClosed,
+	}
+}
+
+// Execute runs fn if the breaker allows it, and records the outcome. It
+// returns ErrCircuitOpen without calling fn if the breaker is open.
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	if err := cb.beforeRequest(); err != nil {
+		return err
+	}
+
+	err := fn()
+	cb.afterRequest(err == nil)
+	return err
+}
+
+func (cb *CircuitBreaker) beforeRequest() error {
+	cb.mu.Lock()
+	var transitioned bool
+	var from, to BreakerState
+
+	switch cb.state {
+	case StateOpen:
+		if time.Since(cb.openedAt) < cb.config.OpenTimeout {
+			cb.mu.Unlock()
+			return ErrCircuitOpen
+		}
+		from, to = cb.state, StateHalfOpen
+		cb.state = to
+		cb.halfOpenInFlight = 1
+		transitioned = true
+	case StateHalfOpen:
+		if cb.halfOpenInFlight >= cb.config.HalfOpenMaxRequests {
+			cb.mu.Unlock()
+			return ErrCircuitOpen
+		}
+		cb.halfOpenInFlight++
+	}
+	cb.mu.Unlock()
+
+	if transitioned {
+		cb.notify(from, to)
+	}
+	return nil
+}
+
+func (cb *CircuitBreaker) afterRequest(success bool) {
+	cb.mu.Lock()
+	var transitioned bool
+	var from, to BreakerState
+
+	switch cb.state {
+	case StateHalfOpen:
+		cb.halfOpenInFlight--
+		if success {
+			from, to = cb.state, StateClosed
+			cb.state = to
+			cb.consecutiveFails = 0
+		} else {
+			from, to = cb.state, StateOpen
+			cb.state = to
+			cb.openedAt = time.Now()
+		}
+		transitioned = true
+	case StateClosed:
+		if success {
+			cb.consecutiveFails = 0
+			break
+		}
+		cb.consecutiveFails++
+		if cb.consecutiveFails >= cb.config.FailureThreshold {
+			from, to = cb.state, StateOpen
+			cb.state = to
+			cb.openedAt = time.Now()
+			transitioned = true
+		}
+	}
+	cb.mu.Unlock()
+
+	if transitioned {
+		cb.notify(from, to)
+	}
+}
+
+// notify invokes onStateChange outside of cb.mu, since the callback may do
+// its own work (e.g. writing a SecurityEvent row) that shouldn't block other
+// goroutines calling Execute.
+func (cb *CircuitBreaker) notify(from, to BreakerState) {
+	if cb.onStateChange != nil && from != to {
+		cb.onStateChange(from, to)
+	}
+}
+
+// State reports the breaker's current state, mainly for health checks/tests.
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
@@ -0,0 +1,89 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	var transitions []BreakerState
+	cb := NewCircuitBreaker(BreakerConfig{
+		FailureThreshold:    3,
+		OpenTimeout:         time.Minute,
+		HalfOpenMaxRequests: 1,
+	}, func(from, to BreakerState) {
+		transitions = append(transitions, to)
+	})
+
+	failing := func() error { return errors.New("boom") }
+
+	for i := 0; i < 2; i++ {
+		err := cb.Execute(failing)
+		assert.Error(t, err)
+		assert.Equal(t, StateClosed, cb.State())
+	}
+
+	err := cb.Execute(failing)
+	assert.Error(t, err)
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, []BreakerState{StateOpen}, transitions)
+
+	err = cb.Execute(func() error { return nil })
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_HalfOpenRecoversToClosed(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerConfig{
+		FailureThreshold:    1,
+		OpenTimeout:         time.Millisecond,
+		HalfOpenMaxRequests: 1,
+	}, nil)
+
+	assert.Error(t, cb.Execute(func() error { return errors.New("boom") }))
+	assert.Equal(t, StateOpen, cb.State())
+
+	time.Sleep(5 * time.Millisecond)
+
+	assert.NoError(t, cb.Execute(func() error { return nil }))
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerConfig{
+		FailureThreshold:    1,
+		OpenTimeout:         time.Millisecond,
+		HalfOpenMaxRequests: 1,
+	}, nil)
+
+	assert.Error(t, cb.Execute(func() error { return errors.New("boom") }))
+	time.Sleep(5 * time.Millisecond)
+
+	assert.Error(t, cb.Execute(func() error { return errors.New("still broken") }))
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestBulkhead_CapsConcurrentAcquires(t *testing.T) {
+	b := NewBulkhead(1)
+
+	assert.NoError(t, b.Acquire(context.Background()))
+
+	done := make(chan struct{})
+	go func() {
+		_ = b.Acquire(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("second Acquire should have blocked while the bulkhead is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	b.Release()
+	<-done
+	b.Release()
+}
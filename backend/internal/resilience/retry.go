@@ -0,0 +1,55 @@
+package resilience
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryConfig bounds an exponential-backoff-with-jitter retry loop.
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Retry calls fn until it succeeds, isRetryable(err) returns false, ctx is
+// cancelled, or MaxAttempts is exhausted - whichever happens first. Callers
+// should only use this for idempotent operations (GETs, or calls the AI
+// service is known to safely no-op on retry).
+func Retry(ctx context.Context, config RetryConfig, isRetryable func(error) bool, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < config.MaxAttempts; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		if !isRetryable(err) {
+			return err
+		}
+		if attempt == config.MaxAttempts-1 {
+			break
+		}
+
+		select {
+		case <-time.After(backoffDelay(config, attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// backoffDelay doubles BaseDelay per attempt, caps at MaxDelay, then picks a
+// random wait between half of that delay and the full delay (jitter), so many
+// concurrent callers retrying together don't all land on the downstream
+// service at the same instant.
+func backoffDelay(config RetryConfig, attempt int) time.Duration {
+	delay := config.BaseDelay * time.Duration(1<<uint(attempt))
+	if delay > config.MaxDelay || delay <= 0 {
+		delay = config.MaxDelay
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	return delay/2 + jitter
+}
@@ -2,10 +2,13 @@ package services
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	"healthsecure/internal/errs"
 	"healthsecure/internal/models"
 
 	"gorm.io/gorm"
@@ -23,8 +26,10 @@ type ThreadConfig struct {
 }
 
 type FeedbackRequest struct {
-	MessageID string `json:"message_id" binding:"required"`
-	Feedback  string `json:"feedback" binding:"required,oneof=thumbs_up thumbs_down"`
+	MessageID  string `json:"message_id" binding:"required"`
+	Feedback   string `json:"feedback" binding:"required,oneof=thumbs_up thumbs_down"`
+	Comment    string `json:"comment"`
+	Correction string `json:"correction"`
 }
 
 func NewChatThreadService(db *gorm.DB, auditService *AuditService) *ChatThreadService {
@@ -59,7 +64,7 @@ func (s *ChatThreadService) CreateThread(userID string, title string) (*models.C
 	}
 
 	if err := s.db.Create(thread).Error; err != nil {
-		return nil, fmt.Errorf("failed to create thread: %w", err)
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to create thread")
 	}
 
 	// Log thread creation
@@ -81,8 +86,11 @@ func (s *ChatThreadService) CreateThread(userID string, title string) (*models.C
 func (s *ChatThreadService) GetThread(threadID, userID string) (*models.ChatThread, error) {
 	var thread models.ChatThread
 	err := s.db.Where("thread_id = ? AND user_id = ?", threadID, userID).First(&thread).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errs.New(errs.ErrNotFound, "thread not found or access denied")
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get thread: %w", err)
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to get thread")
 	}
 	return &thread, nil
 }
@@ -98,7 +106,7 @@ func (s *ChatThreadService) GetUserThreads(userID string, limit int) ([]models.C
 	}
 
 	if err := query.Find(&threads).Error; err != nil {
-		return nil, fmt.Errorf("failed to get user threads: %w", err)
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to get user threads")
 	}
 
 	return threads, nil
@@ -119,7 +127,7 @@ func (s *ChatThreadService) SaveMessage(threadID, role, content, runID string) (
 	}
 
 	if err := s.db.Create(message).Error; err != nil {
-		return nil, fmt.Errorf("failed to save message: %w", err)
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to save message")
 	}
 
 	// Update thread's updated_at timestamp
@@ -139,7 +147,7 @@ func (s *ChatThreadService) GetThreadMessages(threadID, userID string, limit int
 	query := s.db.Where("thread_id = ?", threadID).Order("created_at DESC").Limit(limit)
 
 	if err := query.Find(&messages).Error; err != nil {
-		return nil, fmt.Errorf("failed to get thread messages: %w", err)
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to get thread messages")
 	}
 
 	// Reverse the order to be ascending
@@ -164,13 +172,13 @@ func (s *ChatThreadService) GetThreadMessagesPage(threadID, userID string, page,
 
 	// Get total count
 	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count thread messages: %w", err)
+		return nil, 0, errs.Wrap(errs.ErrInternal, err, "failed to count thread messages")
 	}
 
 	// Paginate
 	offset := (page - 1) * pageSize
 	if err := query.Order("created_at ASC").Offset(offset).Limit(pageSize).Find(&messages).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to get thread messages: %w", err)
+		return nil, 0, errs.Wrap(errs.ErrInternal, err, "failed to get thread messages")
 	}
 
 	return messages, total, nil
@@ -189,23 +197,95 @@ func (s *ChatThreadService) CreateThreadConfig(threadID, userID string) *ThreadC
 	}
 }
 
-// SubmitFeedback records feedback for a message
-func (s *ChatThreadService) SubmitFeedback(messageID, userID, feedback string) error {
+// StartRun persists a ChatRun row for a newly-started traced turn: runID is
+// the caller-generated UUIDv4, parentRunID is set when this run was spawned
+// by another (e.g. a tool call), and tags are flattened to a comma-separated
+// column per this service's existing convention for list-valued fields.
+func (s *ChatThreadService) StartRun(runID, threadID string, parentRunID *string, inputs string, tags []string) (*models.ChatRun, error) {
+	run := &models.ChatRun{
+		RunID:       runID,
+		ParentRunID: parentRunID,
+		ThreadID:    threadID,
+		StartTime:   time.Now(),
+		Inputs:      inputs,
+		Status:      "running",
+	}
+	if len(tags) > 0 {
+		run.Tags = strings.Join(tags, ",")
+	}
+
+	if err := s.db.Create(run).Error; err != nil {
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to start chat run")
+	}
+
+	return run, nil
+}
+
+// CompleteRun closes out a ChatRun started by StartRun, recording its final
+// outputs and status ("completed" or "error").
+func (s *ChatThreadService) CompleteRun(runID, outputs, status string) error {
+	now := time.Now()
+	result := s.db.Model(&models.ChatRun{}).Where("run_id = ?", runID).Updates(map[string]interface{}{
+		"outputs":  outputs,
+		"status":   status,
+		"end_time": &now,
+	})
+	if result.Error != nil {
+		return errs.Wrap(errs.ErrInternal, result.Error, "failed to complete chat run")
+	}
+	if result.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "chat run not found")
+	}
+	return nil
+}
+
+// SubmitFeedbackByRun looks up the message tagged with runID and delegates to
+// SubmitFeedback, so dashboards that only know a RunID (rather than a
+// MessageID) can still record feedback against it.
+func (s *ChatThreadService) SubmitFeedbackByRun(runID, userID, feedback, comment, correction string) (*models.ChatMessage, error) {
+	var message models.ChatMessage
+	if err := s.db.Joins("JOIN chat_threads ON chat_messages.thread_id = chat_threads.thread_id").
+		Where("chat_messages.run_id = ? AND chat_threads.user_id = ?", runID, userID).
+		First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.New(errs.ErrNotFound, "run not found or access denied")
+		}
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to look up run")
+	}
+
+	return s.SubmitFeedback(message.MessageID, userID, feedback, comment, correction)
+}
+
+// SubmitFeedback records feedback for a message, along with an optional free-
+// text comment and, for a correction, what the response should have said.
+// The updated message is returned so callers can forward its RunID to the
+// tracing backend (e.g. AIServiceClient.SubmitFeedback).
+func (s *ChatThreadService) SubmitFeedback(messageID, userID, feedback, comment, correction string) (*models.ChatMessage, error) {
 	// Verify the message belongs to the user's thread
 	var message models.ChatMessage
 	if err := s.db.Joins("JOIN chat_threads ON chat_messages.thread_id = chat_threads.thread_id").
 		Where("chat_messages.message_id = ? AND chat_threads.user_id = ?", messageID, userID).
 		First(&message).Error; err != nil {
-		return fmt.Errorf("message not found or access denied: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.New(errs.ErrNotFound, "message not found or access denied")
+		}
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to look up message")
 	}
 
 	// Update feedback
 	now := time.Now()
-	if err := s.db.Model(&message).Updates(map[string]interface{}{
+	updates := map[string]interface{}{
 		"feedback":    feedback,
 		"feedback_at": &now,
-	}).Error; err != nil {
-		return fmt.Errorf("failed to save feedback: %w", err)
+	}
+	if comment != "" {
+		updates["feedback_comment"] = comment
+	}
+	if correction != "" {
+		updates["feedback_correction"] = correction
+	}
+	if err := s.db.Model(&message).Updates(updates).Error; err != nil {
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to save feedback")
 	}
 
 	// Log feedback submission
@@ -220,7 +300,7 @@ func (s *ChatThreadService) SubmitFeedback(messageID, userID, feedback string) e
 		fmt.Sprintf("Submitted %s feedback for message %s", feedback, messageID),
 	)
 
-	return nil
+	return &message, nil
 }
 
 // GetMessageFeedback retrieves feedback statistics
@@ -241,7 +321,7 @@ func (s *ChatThreadService) GetMessageFeedback(userID string, days int) (map[str
 	}
 
 	if err := query.Scan(&results).Error; err != nil {
-		return nil, fmt.Errorf("failed to get feedback statistics: %w", err)
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to get feedback statistics")
 	}
 
 	stats := make(map[string]int)
@@ -252,6 +332,61 @@ func (s *ChatThreadService) GetMessageFeedback(userID string, days int) (map[str
 	return stats, nil
 }
 
+// FeedbackExportRecord is one row of ExportThumbsDownFeedback's output: a
+// thumbs-down response paired with the user turn that preceded it and
+// whatever correction/comment the reviewer left.
+type FeedbackExportRecord struct {
+	ThreadID   string    `json:"thread_id"`
+	MessageID  string    `json:"message_id"`
+	UserTurn   string    `json:"user_turn"`
+	Response   string    `json:"response"`
+	Comment    string    `json:"comment,omitempty"`
+	Correction string    `json:"correction,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ExportThumbsDownFeedback returns every thumbs_down message created at or
+// after since, each paired with its preceding user turn, for building a
+// fine-tuning or eval set from reviewed corrections.
+func (s *ChatThreadService) ExportThumbsDownFeedback(since time.Time) ([]FeedbackExportRecord, error) {
+	var messages []models.ChatMessage
+	if err := s.db.Where("feedback = ? AND created_at >= ?", "thumbs_down", since).
+		Order("created_at ASC").
+		Find(&messages).Error; err != nil {
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to query thumbs-down feedback")
+	}
+
+	records := make([]FeedbackExportRecord, 0, len(messages))
+	for _, m := range messages {
+		var precedingUser models.ChatMessage
+		err := s.db.Where("thread_id = ? AND role = ? AND created_at < ?", m.ThreadID, "user", m.CreatedAt).
+			Order("created_at DESC").
+			First(&precedingUser).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.Wrap(errs.ErrInternal, err, "failed to load preceding user turn")
+		}
+
+		record := FeedbackExportRecord{
+			ThreadID:  m.ThreadID,
+			MessageID: m.MessageID,
+			Response:  m.Content,
+			CreatedAt: m.CreatedAt,
+		}
+		if err == nil {
+			record.UserTurn = precedingUser.Content
+		}
+		if m.FeedbackComment != nil {
+			record.Comment = *m.FeedbackComment
+		}
+		if m.FeedbackCorrection != nil {
+			record.Correction = *m.FeedbackCorrection
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
 // ArchiveThread marks a thread as inactive
 func (s *ChatThreadService) ArchiveThread(threadID, userID string) error {
 	result := s.db.Model(&models.ChatThread{}).
@@ -259,11 +394,11 @@ func (s *ChatThreadService) ArchiveThread(threadID, userID string) error {
 		Update("is_active", false)
 
 	if result.Error != nil {
-		return fmt.Errorf("failed to archive thread: %w", result.Error)
+		return errs.Wrap(errs.ErrInternal, result.Error, "failed to archive thread")
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("thread not found or access denied")
+		return errs.New(errs.ErrNotFound, "thread not found or access denied")
 	}
 
 	// Log thread archival
@@ -288,13 +423,13 @@ func (s *ChatThreadService) CleanupOldThreads(days int) error {
 	// Delete messages first (foreign key constraint)
 	if err := s.db.Where("thread_id IN (SELECT thread_id FROM chat_threads WHERE is_active = ? AND updated_at < ?)", false, cutoff).
 		Delete(&models.ChatMessage{}).Error; err != nil {
-		return fmt.Errorf("failed to delete old messages: %w", err)
+		return errs.Wrap(errs.ErrInternal, err, "failed to delete old messages")
 	}
 
 	// Delete threads
 	if err := s.db.Where("is_active = ? AND updated_at < ?", false, cutoff).
 		Delete(&models.ChatThread{}).Error; err != nil {
-		return fmt.Errorf("failed to delete old threads: %w", err)
+		return errs.Wrap(errs.ErrInternal, err, "failed to delete old threads")
 	}
 
 	return nil
@@ -321,11 +456,11 @@ func (s *ChatThreadService) UpdateThreadTitle(threadID, userID, newTitle string)
 		Update("title", newTitle)
 
 	if result.Error != nil {
-		return fmt.Errorf("failed to update thread title: %w", result.Error)
+		return errs.Wrap(errs.ErrInternal, result.Error, "failed to update thread title")
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("thread not found or access denied")
+		return errs.New(errs.ErrNotFound, "thread not found or access denied")
 	}
 
 	return nil
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"healthsecure/internal/errs"
+	"healthsecure/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// defaultGrantTTL bounds how long an approved break-glass grant stays active
+// before a clinician must request a new one.
+const defaultGrantTTL = 4 * time.Hour
+
+// EmergencyAccessService manages break-glass EmergencyAccessGrant records:
+// requesting, approving, revoking, and checking whether a grant currently
+// authorizes a doctor or nurse to bypass MedicalRecord's normal role
+// restrictions for a given patient.
+type EmergencyAccessService struct {
+	db           *gorm.DB
+	auditService *AuditService
+}
+
+func NewEmergencyAccessService(db *gorm.DB, auditService *AuditService) *EmergencyAccessService {
+	return &EmergencyAccessService{
+		db:           db,
+		auditService: auditService,
+	}
+}
+
+// RequestAccess creates a pending grant for userID to access patientID's
+// records. The grant does not authorize any access until Approve is called.
+func (s *EmergencyAccessService) RequestAccess(userID, patientID uint, reason string) (*models.EmergencyAccessGrant, error) {
+	grant := &models.EmergencyAccessGrant{
+		UserID:    userID,
+		PatientID: patientID,
+		Reason:    reason,
+		GrantedAt: time.Now(),
+		ExpiresAt: time.Now().Add(defaultGrantTTL),
+	}
+
+	if err := s.db.Create(grant).Error; err != nil {
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to create emergency access request")
+	}
+
+	s.auditService.LogUserAction(
+		userID,
+		models.ActionCreate,
+		fmt.Sprintf("emergency_access_grant:%d", grant.ID),
+		"",
+		"",
+		true,
+		fmt.Sprintf("Requested emergency access to patient %d: %s", patientID, reason),
+	)
+
+	return grant, nil
+}
+
+// Approve marks grant id as approved by approverID, making it active until
+// ExpiresAt. Returns errs.ErrNotFound if no such grant exists, and
+// errs.ErrNoPermission if approverID is the same user who filed the
+// request - self-approval would turn the request/approve workflow into a
+// no-op, since the caller could otherwise grant themselves break-glass
+// access with no independent review.
+func (s *EmergencyAccessService) Approve(id uint, approverID uint) (*models.EmergencyAccessGrant, error) {
+	grant, err := s.getByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if approverID == grant.UserID {
+		return nil, errs.New(errs.ErrNoPermission, "cannot approve your own emergency access request")
+	}
+
+	grant.ApprovedBy = &approverID
+	if err := s.db.Model(grant).Update("approved_by", approverID).Error; err != nil {
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to approve emergency access request")
+	}
+
+	s.auditService.LogUserAction(
+		approverID,
+		models.ActionUpdate,
+		fmt.Sprintf("emergency_access_grant:%d", grant.ID),
+		"",
+		"",
+		true,
+		fmt.Sprintf("Approved emergency access grant %d for user %d", grant.ID, grant.UserID),
+	)
+
+	return grant, nil
+}
+
+// Revoke ends grant id's access immediately, regardless of ExpiresAt.
+func (s *EmergencyAccessService) Revoke(id uint, revokedBy uint) error {
+	grant, err := s.getByID(id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := s.db.Model(grant).Update("revoked_at", &now).Error; err != nil {
+		return errs.Wrap(errs.ErrInternal, err, "failed to revoke emergency access grant")
+	}
+
+	s.auditService.LogUserAction(
+		revokedBy,
+		models.ActionUpdate,
+		fmt.Sprintf("emergency_access_grant:%d", grant.ID),
+		"",
+		"",
+		true,
+		fmt.Sprintf("Revoked emergency access grant %d", grant.ID),
+	)
+
+	return nil
+}
+
+// ActiveGrants lists every grant currently authorizing a break-glass read.
+func (s *EmergencyAccessService) ActiveGrants() ([]models.EmergencyAccessGrant, error) {
+	var grants []models.EmergencyAccessGrant
+	err := s.db.Where("approved_by IS NOT NULL AND revoked_at IS NULL AND expires_at > ?", time.Now()).
+		Find(&grants).Error
+	if err != nil {
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to list active emergency access grants")
+	}
+	return grants, nil
+}
+
+// IsActive reports whether userID currently holds an approved, unrevoked,
+// unexpired grant for patientID. Callers that need to bypass
+// MedicalRecord.CanBeAccessedByRole/SanitizeForRole consult this before
+// doing so, and must log the resulting read via LogOverrideAccess.
+func (s *EmergencyAccessService) IsActive(userID, patientID uint) bool {
+	var grant models.EmergencyAccessGrant
+	err := s.db.Where("user_id = ? AND patient_id = ? AND approved_by IS NOT NULL AND revoked_at IS NULL AND expires_at > ?",
+		userID, patientID, time.Now()).
+		Order("granted_at DESC").
+		First(&grant).Error
+	return err == nil
+}
+
+// LogOverrideAccess records that userID read patientID's record under
+// grantID's break-glass authority, bypassing the normal role restrictions.
+func (s *EmergencyAccessService) LogOverrideAccess(grantID, userID, patientID uint, ip, userAgent string) {
+	s.auditService.LogUserAction(
+		userID,
+		models.ActionRead,
+		fmt.Sprintf("patient:%d", patientID),
+		ip,
+		userAgent,
+		true,
+		fmt.Sprintf("emergency_override=true grant=%d", grantID),
+	)
+}
+
+func (s *EmergencyAccessService) getByID(id uint) (*models.EmergencyAccessGrant, error) {
+	var grant models.EmergencyAccessGrant
+	err := s.db.First(&grant, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errs.New(errs.ErrNotFound, "emergency access grant not found")
+	}
+	if err != nil {
+		return nil, errs.Wrap(errs.ErrInternal, err, "failed to look up emergency access grant")
+	}
+	return &grant, nil
+}